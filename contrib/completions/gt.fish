@@ -1,10 +1,10 @@
 # fish completion for gt                                  -*- shell-script -*-
 
-set -l commands list ls trash tr clean cl restore re
-set -l already_in_trash_commands list ls clean cl restore re
+set -l commands list ls trash tr clean cl restore re tag grep cat diff
+set -l already_in_trash_commands list ls clean cl restore re tag grep cat diff
 set -l trash_commands trash tr
 set -l list_commands list ls
-set -l clean_restore_commands clean cl restore re
+set -l clean_restore_commands clean cl restore re tag
 set -l log_levels debug info warn error fatal
 
 # commands
@@ -12,12 +12,21 @@ complete -c gt -f -n "not __fish_seen_subcommand_from $commands" -a "list ls" -d
 complete -c gt -F -n "not __fish_seen_subcommand_from $commands" -a "trash tr" -d "trash a file or files"
 complete -c gt -f -n "not __fish_seen_subcommand_from $commands" -a "restore re" -d "restore files from trash"
 complete -c gt -f -n "not __fish_seen_subcommand_from $commands" -a "clean cl" -d "clean files from trash"
+complete -c gt -f -n "not __fish_seen_subcommand_from $commands" -a "tag" -d "change notes and tags of trashed files"
+complete -c gt -f -n "not __fish_seen_subcommand_from $commands" -a "grep" -d "search the contents of trashed files"
+complete -c gt -f -n "not __fish_seen_subcommand_from $commands" -a "cat" -d "print the contents of trashed files"
+complete -c gt -f -n "not __fish_seen_subcommand_from $commands" -a "diff" -d "diff trashed files against their original paths"
 
 # global flags
 complete -c gt -n "not __fish_seen_subcommand_from $commands" -l help -s h -d "show help"
 complete -c gt -n "not __fish_seen_subcommand_from $commands" -l version -s v -d "show version"
 complete -c gt -n "not __fish_seen_subcommand_from $commands" -l confirm -s c -d "ask for confirmation"
 complete -c gt -n "not __fish_seen_subcommand_from $commands" -l log -s l -d "log level" -fra (string join " " $log_levels)
+complete -c gt -n "not __fish_seen_subcommand_from $commands" -l yes -s y -d "answer yes to every question"
+complete -c gt -n "not __fish_seen_subcommand_from $commands" -l assume-no -d "answer no to every question"
+complete -c gt -n "not __fish_seen_subcommand_from $commands" -l no-tty -d "answer when there's no terminal" -fra "fail yes no"
+complete -c gt -n "not __fish_seen_subcommand_from $commands" -l color -d "color file names with LS_COLORS" -fra "auto always never"
+complete -c gt -n "not __fish_seen_subcommand_from $commands" -l no-mouse -d "don't use the mouse in the table"
 
 # everyone flags
 complete -c gt -rf -n "__fish_seen_subcommand_from $commands" -l match -s m -d "operate on files matching regex pattern"
@@ -37,12 +46,45 @@ complete -c gt -rf -n "__fish_seen_subcommand_from $commands" -l mode -s x -d "o
 # trash flags
 complete -c gt -rf -n "__fish_seen_subcommand_from $trash_commands" -l recursive -s r -d "recursively trash files"
 complete -c gt -rf -n "__fish_seen_subcommand_from $trash_commands" -l work-dir -s w -d "trash files in specified directory"
+complete -c gt -f -n "__fish_seen_subcommand_from $trash_commands" -l stdin -d "read paths to trash from stdin"
+complete -c gt -rF -n "__fish_seen_subcommand_from $trash_commands" -l files-from -s T -d "read paths to trash from file"
+complete -c gt -f -n "__fish_seen_subcommand_from $trash_commands" -l null -s 0 -d "input paths are NUL separated"
+complete -c gt -rf -n "__fish_seen_subcommand_from $trash_commands" -l note -d "note why files were trashed"
+complete -c gt -rf -n "__fish_seen_subcommand_from $trash_commands" -l tag -d "tag trashed files"
 
 # list flags
 complete -c gt -rf -n "__fish_seen_subcommand_from $list_commands" -l non-interactive -s n -d "list files and quit"
+complete -c gt -f -n "__fish_seen_subcommand_from $list_commands" -l versions -s V -d "list versions of each trashed path and quit"
+complete -c gt -f -n "__fish_seen_subcommand_from $list_commands" -l print0 -d "list only original paths separated by NUL and quit"
+complete -c gt -f -n "__fish_seen_subcommand_from $list_commands" -l deep -d "also list files inside trashed directories"
+
+# restore flags
+complete -c gt -rf -n "__fish_seen_subcommand_from restore re" -l on-conflict -s C -d "what to do when a file exists" -a "ask skip overwrite rename backup merge"
+complete -c gt -f -n "__fish_seen_subcommand_from restore re" -l deep -d "also restore files inside trashed directories"
+complete -c gt -rf -n "__fish_seen_subcommand_from restore re" -l version -d "restore this version of a path" -a "latest oldest"
+complete -c gt -rf -n "__fish_seen_subcommand_from restore re" -l as-of -d "restore a directory tree as it was at date"
+complete -c gt -r -n "__fish_seen_subcommand_from restore re" -l to -s t -d "restore files into this directory" -a "(__fish_complete_directories)"
+complete -c gt -f -n "__fish_seen_subcommand_from restore re" -l keep-structure -s k -d "keep original layout under --to"
+complete -c gt -rf -n "__fish_seen_subcommand_from restore re" -l merge-conflict -d "what to do when a file exists while merging" -a "ask skip overwrite rename backup"
+complete -c gt -rf -n "__fish_seen_subcommand_from restore re" -l rename-template -d "template for renamed files"
+
+# tag flags
+complete -c gt -rf -n "__fish_seen_subcommand_from tag" -l add -d "add tag to files"
+complete -c gt -rf -n "__fish_seen_subcommand_from tag" -l remove -d "remove tag from files"
+complete -c gt -rf -n "__fish_seen_subcommand_from tag" -l note -d "replace files' notes"
+complete -c gt -f -n "__fish_seen_subcommand_from tag" -l clear-note -d "remove files' notes"
+
+# grep flags
+complete -c gt -f -n "__fish_seen_subcommand_from grep" -l ignore-case -s i -d "match regardless of case"
+complete -c gt -f -n "__fish_seen_subcommand_from grep" -l binary -d "search binary files too"
+complete -c gt -rf -n "__fish_seen_subcommand_from grep" -l size-limit -d "skip files larger than size"
+complete -c gt -rf -n "__fish_seen_subcommand_from grep" -l workers -s j -d "search n files at a time"
+complete -c gt -f -n "__fish_seen_subcommand_from grep" -l select -d "select matches to restore"
 
 # clean / restore flags
 complete -c gt -rf -n "__fish_seen_subcommand_from $clean_restore_commands" -l all -s a -d "clean / restore all files"
 
 # list / clean / restore flags
 complete -c gt -rf -n "__fish_seen_subcommand_from $already_in_trash_commands" -l original-path -s o -d "operate on files trashed from this directory"
+complete -c gt -rf -n "__fish_seen_subcommand_from $already_in_trash_commands" -l tag -d "operate on files with tag"
+complete -c gt -rf -n "__fish_seen_subcommand_from $already_in_trash_commands" -l note-match -d "operate on files with notes matching regex"
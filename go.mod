@@ -12,7 +12,9 @@ require (
 	github.com/dustin/go-humanize v1.0.1
 	github.com/ijt/go-anytime v1.9.2
 	github.com/lithammer/fuzzysearch v1.1.8
+	github.com/mattn/go-runewidth v0.0.16
 	github.com/moby/sys/mountinfo v0.7.2
+	github.com/muesli/termenv v0.15.2
 	github.com/urfave/cli/v2 v2.27.3
 	gitlab.com/tymonx/go-formatter v1.5.1
 	golang.org/x/term v0.22.0
@@ -35,11 +37,9 @@ require (
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mattn/go-localereader v0.0.1 // indirect
-	github.com/mattn/go-runewidth v0.0.16 // indirect
 	github.com/mitchellh/hashstructure/v2 v2.0.2 // indirect
 	github.com/muesli/ansi v0.0.0-20230316100256-276c6243b2f6 // indirect
 	github.com/muesli/cancelreader v0.2.2 // indirect
-	github.com/muesli/termenv v0.15.2 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
@@ -0,0 +1,141 @@
+// Package config loads user settings from an ini file in the xdg config directory.
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"git.burning.moe/celediel/gt/internal/files"
+
+	"github.com/adrg/xdg"
+	"gopkg.in/ini.v1"
+)
+
+const (
+	dirName  string = "gt"
+	fileName string = "config.ini"
+)
+
+// Path is where the config file is looked for.
+var Path = filepath.Join(xdg.ConfigHome, dirName, fileName)
+
+type Config struct {
+	Prompt  Prompt  `ini:"prompt"`
+	Restore Restore `ini:"restore"`
+	Preview Preview `ini:"preview"`
+	Keys    Keys    `ini:"keys"`
+	Theme   Theme   `ini:"theme"`
+	Table   Table   `ini:"table"`
+}
+
+type Prompt struct {
+	// NoTTY decides what to answer when there's no terminal to ask: fail, yes, or no
+	NoTTY string `ini:"no-tty"`
+}
+
+type Restore struct {
+	// OnConflict is what to do when a file being restored already exists
+	OnConflict string `ini:"on-conflict"`
+	// MergeConflict is what to do with files that exist when merging directories
+	MergeConflict string `ini:"merge-conflict"`
+	// RenameTemplate is used to name files restored with the rename policy
+	RenameTemplate string `ini:"rename-template"`
+}
+
+type Preview struct {
+	// Show opens the interactive table with the preview pane showing
+	Show bool `ini:"show"`
+	// Position is where the preview pane goes: right, bottom, or auto
+	Position string `ini:"position"`
+	// Command is run to preview files instead of the built in previews, if set
+	Command string `ini:"command"`
+}
+
+type Keys struct {
+	// Preset is the keys to start from: default, vim, or emacs
+	Preset string `ini:"preset"`
+	// Bindings are the other keys in the section, action names set to space separated keys
+	Bindings map[string]string `ini:"-"`
+}
+
+type Theme struct {
+	// Name is the built in theme to start from
+	Name string `ini:"name"`
+	// Background is whether the terminal's background is dark, light, or auto to ask it
+	Background string `ini:"background"`
+	// Styles are the other keys in the section, element names set to colors and attributes
+	Styles map[string]string `ini:"-"`
+}
+
+type Table struct {
+	// Columns are the names of the columns the table starts with, separated by spaces
+	Columns string `ini:"columns"`
+	// Tree starts the table with files under the directories they were trashed from
+	Tree bool `ini:"tree"`
+	// Mouse lets the table be clicked and scrolled with the mouse
+	Mouse bool `ini:"mouse"`
+}
+
+// Default returns the settings used when there is no config file.
+func Default() *Config {
+	return &Config{
+		Prompt: Prompt{
+			NoTTY: "fail",
+		},
+		Restore: Restore{
+			OnConflict:     "ask",
+			MergeConflict:  "skip",
+			RenameTemplate: files.DefaultRenameTemplate,
+		},
+		Preview: Preview{
+			Position: "auto",
+		},
+		Theme: Theme{
+			Name:       "default",
+			Background: "auto",
+		},
+		Table: Table{
+			Columns: "name path trashed size id note",
+			Mouse:   true,
+		},
+	}
+}
+
+// Load reads the config file at Path on top of the defaults. A missing file is not an error.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if _, err := os.Stat(Path); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	// hex colors start with #, so only comments on their own lines are comments
+	file, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, Path)
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := file.MapTo(cfg); err != nil {
+		return cfg, err
+	}
+
+	if section, err := file.GetSection("keys"); err == nil {
+		cfg.Keys.Bindings = map[string]string{}
+		for _, key := range section.Keys() {
+			if key.Name() != "preset" {
+				cfg.Keys.Bindings[key.Name()] = key.String()
+			}
+		}
+	}
+
+	if section, err := file.GetSection("theme"); err == nil {
+		cfg.Theme.Styles = map[string]string{}
+		for _, key := range section.Keys() {
+			if key.Name() != "name" && key.Name() != "background" {
+				cfg.Theme.Styles[key.Name()] = key.String()
+			}
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,186 @@
+// Package diff makes unified diffs of lines of text
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	same   byte = ' '
+	remove byte = '-'
+	add    byte = '+'
+	// maxEdits is the most lines that can be added or removed before giving up, since
+	// what's kept to find them grows with the square of how many there are
+	maxEdits int = 2000
+	// noNewline follows the last line of a file that doesn't end in one, like diff does
+	noNewline string = "\\ No newline at end of file\n"
+)
+
+// ErrTooDifferent is returned when there are too many differences to diff.
+var ErrTooDifferent = fmt.Errorf("more than %d lines differ", maxEdits)
+
+// edit is one line of the difference between a and b, and where it is in each.
+type edit struct {
+	kind byte
+	a, b int
+	line string
+}
+
+// Lines splits text into lines, keeping their newlines, so a last line without one is
+// different from the same line with one.
+func Lines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Unified returns a unified diff turning a into b, with context lines of context
+// around each change, labelled with aName and bName, or nothing if they're the same,
+// or ErrTooDifferent if there are too many changes to find.
+func Unified(aName, bName string, a, b []string, context int) (string, error) {
+	edits, ok := edits(a, b, maxEdits)
+	if !ok {
+		return "", ErrTooDifferent
+	}
+
+	var changes []int
+	for i, e := range edits {
+		if e.kind != same {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	out := strings.Builder{}
+	out.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", aName, bName))
+
+	for i := 0; i < len(changes); {
+		// changes close enough together share a hunk
+		last := i
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*context+1 {
+			last++
+		}
+
+		start := max(changes[i]-context, 0)
+		end := min(changes[last]+context+1, len(edits))
+		hunk := edits[start:end]
+
+		var alen, blen int
+		for _, e := range hunk {
+			if e.kind != add {
+				alen++
+			}
+			if e.kind != remove {
+				blen++
+			}
+		}
+
+		out.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(hunk[0].a, alen), hunkRange(hunk[0].b, blen)))
+		for _, e := range hunk {
+			out.WriteByte(e.kind)
+			out.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				out.WriteString("\n" + noNewline)
+			}
+		}
+
+		i = last + 1
+	}
+
+	return out.String(), nil
+}
+
+// hunkRange formats where a hunk is in one of the files, starting after
+// the 0 based line start, the way diff does.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// edits finds the shortest list of edits turning a into b, with Myers' algorithm, or
+// returns false if there are more than limit of them.
+func edits(a, b []string, limit int) ([]edit, bool) {
+	var (
+		n, m   = len(a), len(b)
+		offset = n + m + 1
+		v      = make([]int, 2*offset+1)
+		// trace is the part of v each step could have reached, from diagonal -d-1 to d+1
+		trace [][]int
+		found bool
+	)
+
+search:
+	for d := 0; d <= min(n+m, limit); d++ {
+		trace = append(trace, slices.Clone(v[offset-d-1:offset+d+2]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break search
+			}
+		}
+	}
+	if !found {
+		return nil, false
+	}
+
+	// go back through the trace to find the path taken
+	var (
+		out  []edit
+		x, y = n, m
+	)
+	for d := len(trace) - 1; d >= 0; d-- {
+		k := x - y
+		// diagonal k was kept at k+d+1
+		at := func(k int) int { return trace[d][k+d+1] }
+
+		var prevk int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevk = k + 1
+		} else {
+			prevk = k - 1
+		}
+		prevx := at(prevk)
+		prevy := prevx - prevk
+
+		for x > prevx && y > prevy {
+			x--
+			y--
+			out = append(out, edit{kind: same, a: x, b: y, line: a[x]})
+		}
+
+		if d > 0 {
+			if x == prevx {
+				out = append(out, edit{kind: add, a: x, b: prevy, line: b[prevy]})
+			} else {
+				out = append(out, edit{kind: remove, a: prevx, b: y, line: a[prevx]})
+			}
+		}
+		x, y = prevx, prevy
+	}
+
+	slices.Reverse(out)
+	return out, true
+}
@@ -0,0 +1,102 @@
+package diff_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"git.burning.moe/celediel/gt/internal/diff"
+)
+
+type testholder struct {
+	name, a, b, want string
+}
+
+func TestUnified(t *testing.T) {
+	for _, tester := range []testholder{
+		{
+			name: "same",
+			a:    "one\ntwo\nthree\n",
+			b:    "one\ntwo\nthree\n",
+			want: "",
+		},
+		{
+			name: "changed",
+			a:    "one\ntwo\nthree\n",
+			b:    "one\n2\nthree\n",
+			want: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n",
+		},
+		{
+			name: "added to empty",
+			a:    "",
+			b:    "one\ntwo\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+one\n+two\n",
+		},
+		{
+			name: "removed everything",
+			a:    "one\n",
+			b:    "",
+			want: "--- a\n+++ b\n@@ -1 +0,0 @@\n-one\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			b:    "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			name: "close enough for one hunk",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:    "one\n2\n3\n4\n5\n6\n7\neight\n",
+			want: "--- a\n+++ b\n@@ -1,8 +1,8 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n",
+		},
+		{
+			name: "inserted in the middle",
+			a:    "a\nb\nc\nd\ne\nf\ng\n",
+			b:    "a\nb\nc\nd\nnew\ne\nf\ng\n",
+			want: "--- a\n+++ b\n@@ -2,6 +2,7 @@\n b\n c\n d\n+new\n e\n f\n g\n",
+		},
+		{
+			name: "newline added at the end",
+			a:    "one\ntwo",
+			b:    "one\ntwo\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n one\n-two\n\\ No newline at end of file\n+two\n",
+		},
+		{
+			name: "newline removed at the end",
+			a:    "one\n",
+			b:    "one",
+			want: "--- a\n+++ b\n@@ -1 +1 @@\n-one\n+one\n\\ No newline at end of file\n",
+		},
+		{
+			name: "neither ends in a newline",
+			a:    "one\ntwo",
+			b:    "1\ntwo",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-one\n+1\n two\n\\ No newline at end of file\n",
+		},
+	} {
+		t.Run(tester.name, func(t *testing.T) {
+			got, err := diff.Unified("a", "b", diff.Lines(tester.a), diff.Lines(tester.b), 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tester.want {
+				t.Fatalf("diff of %q and %q:\ngot:\n%s\nwant:\n%s", tester.a, tester.b, got, tester.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedTooDifferent(t *testing.T) {
+	var a, b strings.Builder
+	for i := range 3000 {
+		fmt.Fprintf(&a, "a%d\n", i)
+		fmt.Fprintf(&b, "b%d\n", i)
+	}
+
+	if _, err := diff.Unified("a", "b", diff.Lines(a.String()), diff.Lines(b.String()), 3); !errors.Is(err, diff.ErrTooDifferent) {
+		t.Fatalf("expected %v, got %v", diff.ErrTooDifferent, err)
+	}
+}
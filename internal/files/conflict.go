@@ -0,0 +1,273 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"git.burning.moe/celediel/gt/internal/prompt"
+
+	"github.com/charmbracelet/log"
+	"gitlab.com/tymonx/go-formatter/formatter"
+)
+
+const (
+	DefaultRenameTemplate string = "{name} (restored {date}){ext}"
+	renameDateFmt         string = "2006-01-02"
+	renameTimeFmt         string = "15-04-05"
+	asideFmt              string = ".%s.gt-overwritten"
+)
+
+// Conflict is what to do when restoring a file to a path that already exists.
+type Conflict int
+
+const (
+	Ask Conflict = iota + 1
+	Skip
+	Overwrite
+	Rename
+	Backup
+	Merge
+)
+
+func (c Conflict) String() string {
+	switch c {
+	case Ask:
+		return "ask"
+	case Skip:
+		return "skip"
+	case Overwrite:
+		return "overwrite"
+	case Rename:
+		return "rename"
+	case Backup:
+		return "backup"
+	case Merge:
+		return "merge"
+	default:
+		return "0"
+	}
+}
+
+// ParseConflict parses the name of a conflict policy.
+func ParseConflict(input string) (Conflict, error) {
+	for c := Ask; c <= Merge; c++ {
+		if strings.EqualFold(input, c.String()) {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown conflict policy '%s' (possible values: skip, overwrite, rename, backup, merge, ask)", input)
+}
+
+// RestoreOptions controls how files are restored.
+type RestoreOptions struct {
+	OnConflict Conflict
+	// MergeConflict is what to do with each file that already exists
+	// when merging a directory into an existing one
+	MergeConflict  Conflict
+	RenameTemplate string
+	// To restores files into this directory instead of where they were trashed from
+	To string
+	// KeepStructure keeps files' original layout, relative to their common parent, under To
+	KeepStructure bool
+}
+
+// restoreReport counts what happened during a restore.
+type restoreReport struct {
+	restored, skipped int
+	merged            []string // entries moved into existing directories
+	leftover          []string // entries left in the trash after merging
+	warnings          []string // what couldn't be put back how it was, after restoring
+}
+
+func (r restoreReport) String() string {
+	out := fmt.Sprintf("restored %d files", r.restored)
+	if len(r.merged) > 0 {
+		out += fmt.Sprintf(", merged %d entries", len(r.merged))
+	}
+	if r.skipped > 0 {
+		out += fmt.Sprintf(", skipped %d", r.skipped)
+	}
+	for _, left := range r.leftover {
+		out += "\nleft in trash: " + left
+	}
+	for _, warning := range r.warnings {
+		out += "\nwarning: " + warning
+	}
+	return out
+}
+
+// resolveConflict decides what to do with a file or directory, which would be restored
+// to the existing outpath. It returns the path to restore to, or skip if it shouldn't be.
+// When it's to be overwritten or backed up, what's at outpath is moved aside, to be put
+// back with putBack if restoring fails, or deleted or trashed with dropAside once it's done.
+func resolveConflict(isdir bool, outpath string, policy Conflict, template string) (newpath, aside string, skip bool, err error) {
+	switch policy {
+	case Skip:
+		log.Infof("%s exists, skipping", outpath)
+		return outpath, "", true, nil
+	case Overwrite:
+		aside, err = moveAside(outpath)
+		return outpath, aside, false, err
+	case Rename:
+		newpath, err = renamed(outpath, template)
+		return newpath, "", false, err
+	case Backup:
+		aside, err = moveAside(outpath)
+		return outpath, aside, false, err
+	case Merge:
+		if existing, e := os.Lstat(outpath); e == nil && existing.IsDir() && isdir {
+			return outpath, "", false, nil
+		}
+		// only directories can be merged, anything else gets renamed
+		newpath, err = renamed(outpath, template)
+		return newpath, "", false, err
+	default:
+		newpath, skip, err = prompt.NewPath(outpath)
+		if err != nil || skip {
+			return newpath, "", skip, err
+		}
+		if newpath == outpath {
+			// chose to overwrite
+			aside, err = moveAside(outpath)
+		}
+		return newpath, aside, false, err
+	}
+}
+
+// moveAside moves path out of the way of what's being restored over it, to a hidden
+// name next to it, and returns where it went.
+func moveAside(path string) (string, error) {
+	var (
+		dir   = filepath.Dir(path)
+		base  = fmt.Sprintf(asideFmt, filepath.Base(path))
+		aside = filepath.Join(dir, base)
+	)
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(aside); os.IsNotExist(err) {
+			break
+		}
+		aside = filepath.Join(dir, fmt.Sprintf("%s-%d", base, i))
+	}
+
+	log.Infof("%s exists, moving it to %s to overwrite it", path, aside)
+	return aside, os.Rename(path, aside)
+}
+
+// putBack moves what was moved aside back to path, after restoring over it failed
+// with err, and returns err.
+func putBack(aside, path string, err error) error {
+	if aside == "" {
+		return err
+	}
+	if e := os.Rename(aside, path); e != nil {
+		return fmt.Errorf("%w (and couldn't move %s back to %s: %s)", err, aside, path, e)
+	}
+	return err
+}
+
+// dropAside deletes what was moved aside from path, once what's replacing it has been
+// restored, or trashes it as if it was still at path if backup is set.
+func dropAside(aside, path string, backup bool) error {
+	if aside == "" {
+		return nil
+	}
+	if !backup {
+		return os.RemoveAll(aside)
+	}
+
+	log.Infof("trashing %s, what was at %s before restoring over it", aside, path)
+	if err := trashFileAs(aside, path, TrashOptions{}); err != nil {
+		return fmt.Errorf("restored over %s, but couldn't trash what was there, it's at %s: %w", path, aside, err)
+	}
+	return nil
+}
+
+// renamed returns a path that doesn't exist yet for path, formatted with template.
+func renamed(path, template string) (string, error) {
+	if template == "" {
+		template = DefaultRenameTemplate
+	}
+
+	var (
+		now  = time.Now()
+		dir  = filepath.Dir(path)
+		ext  = filepath.Ext(path)
+		name = strings.TrimSuffix(filepath.Base(path), ext)
+	)
+
+	base, err := formatter.Format(template, formatter.Named{
+		"name": name,
+		"ext":  ext,
+		"date": now.Format(renameDateFmt),
+		"time": now.Format(renameTimeFmt),
+	})
+	if err != nil {
+		return path, err
+	}
+
+	newpath := filepath.Join(dir, base)
+	bext := filepath.Ext(base)
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(newpath); os.IsNotExist(err) {
+			log.Infof("%s exists, renaming to %s", path, newpath)
+			return newpath, nil
+		}
+		newpath = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(base, bext), i, bext))
+	}
+}
+
+// mergeDir moves everything in src into the existing directory dst, recursing into
+// directories that exist in both, and deciding what to do with other entries that
+// already exist with opts.MergeConflict. Anything not moved is left in src.
+func mergeDir(src, dst string, opts RestoreOptions, report *restoreReport) (leftover bool, err error) {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return true, err
+	}
+
+	for _, entry := range entries {
+		from := filepath.Join(src, entry.Name())
+		to := filepath.Join(dst, entry.Name())
+
+		existing, e := os.Lstat(to)
+		if e == nil && existing.IsDir() && entry.IsDir() {
+			left, err := mergeDir(from, to, opts, report)
+			if err != nil {
+				return true, err
+			}
+			leftover = leftover || left
+			continue
+		}
+
+		var aside string
+		if e == nil {
+			var skip bool
+			to, aside, skip, err = resolveConflict(entry.IsDir(), to, opts.MergeConflict, opts.RenameTemplate)
+			if err != nil {
+				return true, err
+			}
+			if skip {
+				report.leftover = append(report.leftover, from)
+				leftover = true
+				continue
+			}
+		}
+
+		log.Infof("merging %s into %s", from, to)
+		if err := os.Rename(from, to); err != nil {
+			return true, putBack(aside, to, err)
+		}
+		if err := dropAside(aside, to, opts.MergeConflict == Backup); err != nil {
+			return true, err
+		}
+		report.merged = append(report.merged, to)
+	}
+
+	if !leftover {
+		err = os.Remove(src)
+	}
+	return leftover, err
+}
@@ -0,0 +1,170 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenamed(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "a.txt"), "")
+	write(t, filepath.Join(dir, "a (old).txt"), "")
+
+	tests := []struct {
+		template, want string
+	}{
+		{"{name}.bak{ext}", "a.bak.txt"},
+		{"{name} (old){ext}", "a (old) (2).txt"},
+		{"", "a (restored "},
+	}
+
+	for _, test := range tests {
+		t.Run(test.template, func(t *testing.T) {
+			got, err := renamed(filepath.Join(dir, "a.txt"), test.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if base := filepath.Base(got); base[:min(len(base), len(test.want))] != test.want {
+				t.Fatalf("expected '%s', got '%s'", test.want, base)
+			}
+		})
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Conflict
+		isdir    bool
+		wantSkip bool
+		// wantMoved is whether what's in the way is moved, to the new path or aside
+		wantMoved, wantRenamed bool
+	}{
+		{"skip", Skip, false, true, false, false},
+		{"overwrite", Overwrite, false, false, true, false},
+		{"backup", Backup, false, false, true, false},
+		{"rename", Rename, false, false, false, true},
+		{"merge directory", Merge, true, false, false, false},
+		{"merge file", Merge, false, false, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				dir      = t.TempDir()
+				existing = filepath.Join(dir, "d")
+			)
+			write(t, filepath.Join(existing, "kept"), "kept")
+
+			newpath, aside, skip, err := resolveConflict(test.isdir, existing, test.policy, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if skip != test.wantSkip {
+				t.Fatalf("expected skip %t, got %t", test.wantSkip, skip)
+			}
+			if renamed := newpath != existing; renamed != test.wantRenamed {
+				t.Fatalf("expected renamed %t, got %s", test.wantRenamed, newpath)
+			}
+			if (aside != "") != test.wantMoved {
+				t.Fatalf("expected moved aside %t, got '%s'", test.wantMoved, aside)
+			}
+			if aside != "" {
+				if got := read(t, filepath.Join(aside, "kept")); got != "kept" {
+					t.Fatalf("expected what was in the way to be kept aside, got '%s'", got)
+				}
+				if err := putBack(aside, existing, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := read(t, filepath.Join(existing, "kept")); got != "kept" {
+				t.Fatalf("expected what was in the way to still be there, got '%s'", got)
+			}
+		})
+	}
+}
+
+func TestRestoreOverwrite(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		dest = filepath.Join(dir, "file")
+		file = trashed(t, dir, dest, "trashed")
+	)
+	write(t, dest, "existing")
+
+	report, err := restore(Files{file}, RestoreOptions{OnConflict: Overwrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.restored != 1 {
+		t.Fatalf("expected 1 restored, got %s", report)
+	}
+	if got := read(t, dest); got != "trashed" {
+		t.Fatalf("expected the trashed file to be restored, got '%s'", got)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, ".file.gt-overwritten")); !os.IsNotExist(err) {
+		t.Fatalf("expected what was overwritten to be deleted, got %v", err)
+	}
+}
+
+func TestRestoreOverwriteFailed(t *testing.T) {
+	// backups are only trashed once restoring over them has worked
+	for _, policy := range []Conflict{Overwrite, Backup} {
+		var (
+			dir  = t.TempDir()
+			dest = filepath.Join(dir, "d")
+			file = trashed(t, dir, dest, "trashed")
+		)
+		write(t, filepath.Join(dest, "new.txt"), "new")
+
+		// restoring fails after what's in the way is moved aside
+		if err := os.Remove(file.path); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := restore(Files{file}, RestoreOptions{OnConflict: policy}); err == nil {
+			t.Fatalf("%s: expected restoring a missing file to fail", policy)
+		}
+		if got := read(t, filepath.Join(dest, "new.txt")); got != "new" {
+			t.Fatalf("%s: expected what was in the way to be put back, got '%s'", policy, got)
+		}
+		if _, err := os.Lstat(filepath.Join(dir, ".d.gt-overwritten")); !os.IsNotExist(err) {
+			t.Fatalf("%s: expected nothing left aside, got %v", policy, err)
+		}
+	}
+}
+
+func TestMergeDir(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		src = filepath.Join(dir, "src")
+		dst = filepath.Join(dir, "dst")
+	)
+	write(t, filepath.Join(src, "new"), "new")
+	write(t, filepath.Join(src, "both"), "trashed")
+	write(t, filepath.Join(src, "sub", "deep"), "deep")
+	write(t, filepath.Join(dst, "both"), "existing")
+	write(t, filepath.Join(dst, "sub", "other"), "other")
+
+	var report restoreReport
+	leftover, err := mergeDir(src, dst, RestoreOptions{MergeConflict: Skip}, &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !leftover || len(report.leftover) != 1 {
+		t.Fatalf("expected the conflicting file left over, got %v", report.leftover)
+	}
+
+	for path, want := range map[string]string{
+		filepath.Join(dst, "new"):          "new",
+		filepath.Join(dst, "both"):         "existing",
+		filepath.Join(dst, "sub", "deep"):  "deep",
+		filepath.Join(dst, "sub", "other"): "other",
+		filepath.Join(src, "both"):         "trashed",
+	} {
+		if got := read(t, path); got != want {
+			t.Fatalf("expected %s to be '%s', got '%s'", path, want, got)
+		}
+	}
+}
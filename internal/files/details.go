@@ -0,0 +1,136 @@
+package files
+
+import (
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Details is everything there is to know about a file, to show all of it at once.
+type Details struct {
+	Name, Path string
+	// Trash, TrashPath, TrashInfo, and ID are empty for files that aren't in the trash
+	Trash, TrashPath, TrashInfo string
+	ID                          string
+	Trashed, Modified           time.Time
+	Mode                        fs.FileMode
+	// Permissions is Mode like ls shows it, and in octal
+	Permissions  string
+	Owner, Group string
+	// Link is where a symlink points
+	Link  string
+	IsDir bool
+	Size  int64
+	// Entries is how many files and directories there are anywhere inside a directory
+	Entries int
+	Note    string
+	Tags    []string
+	// Err is why the file itself couldn't be read, if it couldn't
+	Err error
+}
+
+// Describe gathers the Details of file, reading what isn't already known from the filesystem.
+func Describe(file File) (details Details) {
+	details = Details{
+		Name:  file.Name(),
+		Path:  file.Path(),
+		IsDir: file.IsDir(),
+		Size:  file.Filesize(),
+	}
+
+	if trash := trashOf(file); trash.trashinfo != "" {
+		details.Trash = TrashDir(file)
+		details.TrashPath = Location(file)
+		details.TrashInfo = trash.trashinfo
+		details.ID = trash.id
+		details.Trashed = trash.trashed
+		details.Note = trash.note
+		details.Tags = trash.tags
+	}
+
+	path := Location(file)
+	info, err := os.Lstat(path)
+	if err != nil {
+		details.Err = err
+		return
+	}
+
+	details.Modified = info.ModTime()
+	details.Mode = info.Mode()
+	details.Permissions = info.Mode().String() + " (" + formatMode(info.Mode()) + ")"
+
+	details.Owner, details.Group = Owner(info)
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		details.Link, _ = os.Readlink(path)
+	}
+
+	if info.IsDir() {
+		details.Size, details.Entries = 0, 0
+		_ = filepath.WalkDir(path, func(p string, dirEntry fs.DirEntry, err error) error {
+			if err != nil || p == path {
+				return nil
+			}
+			details.Entries++
+			if info, err := dirEntry.Info(); err == nil && !info.IsDir() {
+				details.Size += info.Size()
+			}
+			return nil
+		})
+	}
+
+	return
+}
+
+// Owner returns the names of the user and group that own the file info is about, or
+// their ids if they don't have names, or nothing if they can't be read.
+func Owner(info fs.FileInfo) (owner, group string) {
+	uid, gid, ok := OwnerIDs(info)
+	if !ok {
+		return "", ""
+	}
+
+	owner = strconv.Itoa(int(uid))
+	if u, err := user.LookupId(owner); err == nil {
+		owner = u.Username
+	}
+	group = strconv.Itoa(int(gid))
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	return
+}
+
+// OwnerIDs returns the uid and gid of whoever owns the file info is about.
+func OwnerIDs(info fs.FileInfo) (uid, gid uint32, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return stat.Uid, stat.Gid, true
+}
+
+// TrashDir returns the trash directory file is in, or nothing if it isn't in the trash.
+func TrashDir(file File) string {
+	trash := trashOf(file)
+	if trash.trashinfo == "" {
+		return ""
+	}
+	return filepath.Dir(filepath.Dir(trash.trashinfo))
+}
+
+// trashOf returns the trashed file that file is, or is inside of.
+func trashOf(file File) TrashInfo {
+	switch file := file.(type) {
+	case TrashInfo:
+		return file
+	case TrashEntry:
+		return file.trash
+	default:
+		return TrashInfo{}
+	}
+}
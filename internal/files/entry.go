@@ -0,0 +1,190 @@
+package files
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"git.burning.moe/celediel/gt/internal/filter"
+
+	"github.com/charmbracelet/log"
+)
+
+// TrashEntry is a file or directory somewhere inside a trashed directory.
+type TrashEntry struct {
+	name, path, ogpath string
+	trash              TrashInfo
+	isdir              bool
+	modified           time.Time
+	filesize           int64
+	mode               fs.FileMode
+}
+
+func (e TrashEntry) Name() string        { return e.name }
+func (e TrashEntry) TrashPath() string   { return e.path }
+func (e TrashEntry) Path() string        { return e.ogpath }
+func (e TrashEntry) Trash() TrashInfo    { return e.trash }
+func (e TrashEntry) Date() time.Time     { return e.trash.trashed }
+func (e TrashEntry) Modified() time.Time { return e.modified }
+func (e TrashEntry) IsDir() bool         { return e.isdir }
+func (e TrashEntry) Mode() fs.FileMode   { return e.mode }
+func (e TrashEntry) Filesize() int64     { return e.filesize }
+
+func (e TrashEntry) String() string {
+	return e.name + e.path + e.ogpath
+}
+
+// newEntry makes a TrashEntry for the file at path, inside the trashed directory trash.
+// Directories' sizes are left for the caller to add up.
+func newEntry(trash TrashInfo, path string, info fs.FileInfo) TrashEntry {
+	rel, err := filepath.Rel(trash.path, path)
+	if err != nil {
+		rel = info.Name()
+	}
+
+	return TrashEntry{
+		name:     info.Name(),
+		path:     path,
+		ogpath:   filepath.Join(trash.ogpath, rel),
+		trash:    trash,
+		isdir:    info.IsDir(),
+		modified: info.ModTime(),
+		filesize: info.Size(),
+		mode:     info.Mode(),
+	}
+}
+
+// Entries returns what's directly inside a trashed directory, or a directory inside one.
+func Entries(file File) (Files, error) {
+	var trash TrashInfo
+	switch file := file.(type) {
+	case TrashInfo:
+		trash = file
+	case TrashEntry:
+		trash = file.trash
+	default:
+		return nil, fmt.Errorf("%s isn't in the trash", file.Name())
+	}
+
+	if !file.IsDir() {
+		return nil, fmt.Errorf("%s isn't a directory", file.Name())
+	}
+
+	dir := trash.path
+	if entry, ok := file.(TrashEntry); ok {
+		dir = entry.path
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries Files
+	for _, dirEntry := range dirEntries {
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		entry := newEntry(trash, filepath.Join(dir, dirEntry.Name()), info)
+		if entry.isdir {
+			entry.filesize = calculateDirSize(entry.path)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// metadata returns what the directories above the entry were like, so they can be
+// recreated if they're missing when it's restored; those inside the trashed directory
+// as they are in the trash, and those above it as they were when it was trashed.
+func (e TrashEntry) metadata() (meta metadata) {
+	for dir := filepath.Dir(e.path); isUnder(e.trash.path, dir); dir = filepath.Dir(dir) {
+		info, err := os.Lstat(dir)
+		if err != nil {
+			break
+		}
+		meta.parents = append(meta.parents, statMetadata(info))
+	}
+	meta.parents = append(meta.parents, e.trash.meta.parents...)
+	return
+}
+
+// FindDeep finds files in all trashes matching fltr, like FindInAllTrashes, along with
+// anything matching it inside trashed directories. ogdir applies to where entries would
+// be restored to, and notes and tags to those of the directory entries are in.
+func FindDeep(ogdir string, fltr *filter.Filter) Files {
+	var fls, entries Files
+
+	for _, file := range FindInAllTrashes("", &filter.Filter{}) {
+		trash, ok := file.(TrashInfo)
+		if !ok || !fltr.MatchNote(trash.note, trash.tags) {
+			continue
+		}
+
+		if ogdir == "" || filepath.Dir(trash.ogpath) == ogdir {
+			if info, err := os.Lstat(trash.path); err == nil && fltr.Match(info) {
+				fls = append(fls, trash)
+			}
+		}
+		if trash.isdir {
+			entries = append(entries, findEntries(trash, ogdir, fltr)...)
+		}
+	}
+
+	return append(fls, entries...)
+}
+
+// findEntries finds anything matching fltr inside the trashed directory trash, adding
+// up each directory's size from everything under it along the way.
+func findEntries(trash TrashInfo, ogdir string, fltr *filter.Filter) Files {
+	var (
+		found  Files
+		walked []string // directories, each after the one it's in
+		sizes  = map[string]int64{}
+	)
+
+	err := filepath.WalkDir(trash.path, func(path string, dirEntry fs.DirEntry, err error) error {
+		if err != nil || path == trash.path {
+			return err
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			walked = append(walked, path)
+		} else {
+			sizes[filepath.Dir(path)] += info.Size()
+		}
+
+		if !fltr.Match(info) {
+			return nil
+		}
+		entry := newEntry(trash, path, info)
+		if ogdir == "" || filepath.Dir(entry.ogpath) == ogdir {
+			found = append(found, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("error reading trashed directory '%s': %s", trash.path, err)
+	}
+
+	// going backwards, each directory's size is complete before it's added to its parent's
+	for i := len(walked) - 1; i >= 0; i-- {
+		sizes[filepath.Dir(walked[i])] += sizes[walked[i]]
+	}
+	for i, file := range found {
+		if entry := file.(TrashEntry); entry.isdir {
+			entry.filesize = sizes[entry.path]
+			found[i] = entry
+		}
+	}
+
+	return found
+}
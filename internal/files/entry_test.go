@@ -0,0 +1,61 @@
+package files
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"git.burning.moe/celediel/gt/internal/filter"
+)
+
+func TestFindEntries(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		trash = TrashInfo{name: "d", path: filepath.Join(dir, "d"), ogpath: "/home/user/d", isdir: true}
+	)
+	write(t, filepath.Join(trash.path, "a.go"), "12345")
+	write(t, filepath.Join(trash.path, "sub", "b.go"), "123")
+	write(t, filepath.Join(trash.path, "sub", "c.txt"), "1234567")
+	write(t, filepath.Join(trash.path, "sub", "deeper", "d.go"), "1")
+
+	everything, _ := filter.New("", "", "", "", "", "", "", false, false, false, "", "", 0)
+	gofiles, _ := filter.New("", "", "", `*.go`, "", "", "", true, false, false, "", "", 0)
+
+	tests := []struct {
+		name  string
+		ogdir string
+		fltr  *filter.Filter
+		want  map[string]int64
+	}{
+		{"everything", "", everything, map[string]int64{
+			"a.go": 5, "sub": 11, "b.go": 3, "c.txt": 7, "deeper": 1, "d.go": 1,
+		}},
+		{"filtered", "", gofiles, map[string]int64{"a.go": 5, "b.go": 3, "d.go": 1}},
+		{"original path", "/home/user/d/sub", everything, map[string]int64{"b.go": 3, "c.txt": 7, "deeper": 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := map[string]int64{}
+			for _, file := range findEntries(trash, test.ogdir, test.fltr) {
+				got[file.Name()] = file.Filesize()
+			}
+
+			var gotNames, wantNames []string
+			for name := range got {
+				gotNames = append(gotNames, name)
+			}
+			for name, size := range test.want {
+				wantNames = append(wantNames, name)
+				if got[name] != size {
+					t.Fatalf("expected %s to be %d bytes, got %d", name, size, got[name])
+				}
+			}
+			slices.Sort(gotNames)
+			slices.Sort(wantNames)
+			if !slices.Equal(gotNames, wantNames) {
+				t.Fatalf("expected %v, got %v", wantNames, gotNames)
+			}
+		})
+	}
+}
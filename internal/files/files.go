@@ -2,8 +2,11 @@
 package files
 
 import (
+	"bufio"
 	"cmp"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -27,11 +30,37 @@ type File interface {
 type Files []File
 
 func (fls Files) String() string {
+	return fls.Join("\n")
+}
+
+// Join formats each file as a tab separated line of date, name and path, and its id if
+// it's trashed, terminated by end instead of a newline.
+func (fls Files) Join(end string) string {
+	return fls.Format(end, File.Name)
+}
+
+// Format is like Join, with each file's name as name returns it, e.g. colored. Files
+// inside trashed directories have the id of the directory they're in.
+func (fls Files) Format(end string, name func(File) string) string {
 	var out = strings.Builder{}
 	for _, file := range fls {
-		out.WriteString(fmt.Sprintf("%s\t%s\t%s\n",
-			file.Date().Format(time.RFC3339), file.Name(), file.Path(),
+		out.WriteString(fmt.Sprintf("%s\t%s\t%s",
+			file.Date().Format(time.RFC3339), name(file), file.Path(),
 		))
+		if id := idOf(file); id != "" {
+			out.WriteString("\t" + id)
+		}
+		out.WriteString(end)
+	}
+	return out.String()
+}
+
+// Paths is the path each file was trashed from, each terminated by end, and nothing else,
+// for reading back with ReadPaths.
+func (fls Files) Paths(end string) string {
+	var out = strings.Builder{}
+	for _, file := range fls {
+		out.WriteString(file.Path() + end)
 	}
 	return out.String()
 }
@@ -53,6 +82,34 @@ func (fls Files) TotalSize() int64 {
 	return size
 }
 
+// ReadPaths reads a list of paths from r, one per line, or separated
+// by NUL bytes if null is true. Empty entries are skipped.
+func ReadPaths(r io.Reader, null bool) ([]string, error) {
+	var (
+		paths  []string
+		delim  byte = '\n'
+		reader      = bufio.NewReader(r)
+	)
+	if null {
+		delim = 0
+	}
+
+	for {
+		path, err := reader.ReadString(delim)
+		path = strings.TrimSuffix(path, string(delim))
+		if path != "" {
+			paths = append(paths, path)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return paths, err
+		}
+	}
+
+	return paths, nil
+}
+
 func SortByModified(a, b File) int {
 	if a.Date().Before(b.Date()) {
 		return 1
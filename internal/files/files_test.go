@@ -0,0 +1,49 @@
+package files
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPathsReadBack(t *testing.T) {
+	var (
+		// names can have anything but NUL and /, so only NUL can separate them
+		paths = []string{"/home/user/a.txt", "/home/user/new\nline", "/home/user/tab\there", "/home/user/space d"}
+		fls   Files
+	)
+	for i, path := range paths {
+		fls = append(fls, TrashInfo{name: "x", ogpath: path, id: strings.Repeat("a", i+8)})
+	}
+
+	// what list --print0 prints, read like trash --stdin -0 reads it
+	got, err := ReadPaths(strings.NewReader(fls.Paths("\x00")), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, paths) {
+		t.Fatalf("expected %q, got %q", paths, got)
+	}
+}
+
+func TestJoinIDs(t *testing.T) {
+	var (
+		then = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		dir  = TrashInfo{name: "dir", ogpath: "/home/user/dir", trashed: then, id: "abc1234ffff"}
+		fls  = Files{
+			dir,
+			TrashEntry{name: "inside", ogpath: "/home/user/dir/inside", trash: dir, modified: then},
+			DiskFile{name: "here", path: "/home/user/here", modified: then},
+		}
+	)
+
+	// ids are last, so the other fields stay where scripts expect them, and only
+	// trashed files have one
+	want := "2024-06-01T12:00:00Z\tdir\t/home/user/dir\t@abc1234\n" +
+		"2024-06-01T12:00:00Z\tinside\t/home/user/dir/inside\t@abc1234\n" +
+		"2024-06-01T12:00:00Z\there\t/home/user/here\n"
+	if got := fls.Join("\n"); got != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, got)
+	}
+}
@@ -0,0 +1,193 @@
+package files
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// binaryCheckLen is how much of a file to look at for NUL bytes to decide it's binary, like git does
+	binaryCheckLen int = 8000
+	maxLineLen     int = 1024 * 1024
+)
+
+// GrepOptions controls searching the contents of trashed files.
+type GrepOptions struct {
+	Pattern *regexp.Regexp
+	// MaxSize skips files larger than this, or none if it's 0
+	MaxSize int64
+	// Binary searches files that look binary too
+	Binary  bool
+	Workers int
+}
+
+// Match is a line in a trashed file that matches a search.
+type Match struct {
+	Line int
+	Text string
+}
+
+// GrepResult is a trashed file, or a file inside a trashed directory, its matching lines,
+// and why it couldn't all be searched, if it couldn't.
+type GrepResult struct {
+	File    File
+	Matches []Match
+	Err     error
+}
+
+func (r GrepResult) String() string {
+	out := strings.Builder{}
+	out.WriteString(Files{r.File}.String())
+	for _, match := range r.Matches {
+		out.WriteString(fmt.Sprintf("\t%d:\t%s\n", match.Line, match.Text))
+	}
+	return out.String()
+}
+
+// Grep searches the contents of trashed files in fls, and all the files inside trashed
+// directories, for lines matching opts.Pattern. Results are in the same order as fls, and
+// include files that couldn't be searched, with what was found before the error.
+func Grep(fls Files, opts GrepOptions) []GrepResult {
+	var (
+		candidates Files
+		wg         sync.WaitGroup
+		jobs       = make(chan int)
+	)
+
+	for _, file := range fls {
+		candidates = append(candidates, regularFiles(file)...)
+	}
+	results := make([]GrepResult, len(candidates))
+
+	for range max(opts.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				file := candidates[i]
+				matches, err := grepFile(Location(file), opts)
+				results[i] = GrepResult{File: file, Matches: matches, Err: err}
+			}
+		}()
+	}
+
+	for i := range candidates {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var out []GrepResult
+	for _, result := range results {
+		if len(result.Matches) > 0 || result.Err != nil {
+			out = append(out, result)
+		}
+	}
+	return out
+}
+
+// regularFiles returns file if it's a regular file, or the regular files anywhere inside it.
+func regularFiles(file File) (out Files) {
+	var trash TrashInfo
+	switch file := file.(type) {
+	case TrashInfo:
+		trash = file
+	case TrashEntry:
+		trash = file.trash
+	default:
+		return nil
+	}
+
+	root := Location(file)
+	err := filepath.WalkDir(root, func(path string, dirEntry fs.DirEntry, err error) error {
+		if err != nil || !dirEntry.Type().IsRegular() {
+			return err
+		}
+		if path == root {
+			out = append(out, file)
+			return nil
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			return nil
+		}
+		out = append(out, newEntry(trash, path, info))
+		return nil
+	})
+	if err != nil {
+		log.Errorf("error reading trashed directory '%s': %s", root, err)
+	}
+	return
+}
+
+// grepFile returns the lines of the file at path matching opts.Pattern, up to any error.
+func grepFile(path string, opts GrepOptions) (matches []Match, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if opts.MaxSize > 0 {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > opts.MaxSize {
+			log.Infof("%s is larger than %d bytes, skipping", path, opts.MaxSize)
+			return nil, nil
+		}
+	}
+
+	reader := bufio.NewReaderSize(f, binaryCheckLen)
+	if !opts.Binary {
+		head, err := reader.Peek(binaryCheckLen)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		if IsBinary(head) {
+			log.Infof("%s is binary, skipping", path)
+			return nil, nil
+		}
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineLen)
+	line := 1
+	for ; scanner.Scan(); line++ {
+		if text := strings.TrimRight(scanner.Text(), "\r"); opts.Pattern.MatchString(text) {
+			matches = append(matches, Match{Line: line, Text: text})
+		}
+	}
+
+	if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return matches, fmt.Errorf("line %d is longer than %d bytes, stopped searching there", line, maxLineLen)
+	}
+	return matches, scanner.Err()
+}
+
+// Location returns where the contents of file actually are; in the trash for trashed
+// files and files inside trashed directories, or where it is for files on disk.
+func Location(file File) string {
+	switch file := file.(type) {
+	case TrashInfo:
+		return file.path
+	case TrashEntry:
+		return file.path
+	case DiskFile:
+		return file.path
+	default:
+		return ""
+	}
+}
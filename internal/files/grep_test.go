@@ -0,0 +1,30 @@
+package files
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGrepLongLine(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		content = "needle\n" + strings.Repeat("x", maxLineLen+1) + "\nneedle again\n"
+		file    = trashed(t, dir, filepath.Join(dir, "long.txt"), content)
+	)
+
+	results := Grep(Files{file}, GrepOptions{Pattern: regexp.MustCompile("needle")})
+	if len(results) != 1 {
+		t.Fatalf("expected a result for the file, got %v", results)
+	}
+
+	// what was found before the long line is kept, and it's clear the rest wasn't searched
+	result := results[0]
+	if len(result.Matches) != 1 || result.Matches[0].Line != 1 {
+		t.Fatalf("expected the match on line 1, got %v", result.Matches)
+	}
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "line 2") {
+		t.Fatalf("expected an error about line 2, got %v", result.Err)
+	}
+}
@@ -0,0 +1,82 @@
+package files
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	ShortIDLength int    = 7
+	IDPrefix      string = "@"
+)
+
+// trashID makes a stable id for a trashed file from its trash directory and trashinfo file name.
+func trashID(trashdir, infoname string) string {
+	sum := sha1.Sum([]byte(filepath.Join(trashdir, infoname)))
+	return hex.EncodeToString(sum[:])
+}
+
+// idOf is the @id of file, or of the trashed directory it's in, or nothing if it isn't trashed.
+func idOf(file File) string {
+	switch file := file.(type) {
+	case TrashInfo:
+		return IDPrefix + file.ShortID()
+	case TrashEntry:
+		return IDPrefix + file.trash.ShortID()
+	}
+	return ""
+}
+
+// IsID checks if arg looks like an @id rather than a file name.
+func IsID(arg string) bool {
+	return strings.HasPrefix(arg, IDPrefix) && len(arg) > len(IDPrefix)
+}
+
+// ByID finds the files in fls with ids starting with each of ids, which may start with @,
+// along with any files inside the trashed directory with that id. Each id must match
+// exactly one trashed file.
+func ByID(fls Files, ids ...string) (Files, error) {
+	var out Files
+	for _, id := range ids {
+		id = strings.ToLower(strings.TrimPrefix(id, IDPrefix))
+
+		var (
+			matches Files
+			trashed = map[string]TrashInfo{}
+		)
+		for _, file := range fls {
+			var t TrashInfo
+			switch file := file.(type) {
+			case TrashInfo:
+				t = file
+			case TrashEntry:
+				t = file.trash
+			default:
+				continue
+			}
+			if strings.HasPrefix(t.id, id) {
+				matches = append(matches, file)
+				trashed[t.id] = t
+			}
+		}
+
+		switch len(trashed) {
+		case 0:
+			return nil, fmt.Errorf("no file in the trash with id %s%s", IDPrefix, id)
+		case 1:
+			out = append(out, matches...)
+		default:
+			var names []string
+			for _, t := range trashed {
+				names = append(names, IDPrefix+t.ShortID()+" "+t.Name())
+			}
+			slices.Sort(names)
+			return nil, fmt.Errorf("id %s%s is ambiguous: %s", IDPrefix, id, strings.Join(names, ", "))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,89 @@
+package files
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrashID(t *testing.T) {
+	id := trashID("/home/user/.local/share/Trash", "a.txt.trashinfo")
+	if again := trashID("/home/user/.local/share/Trash", "a.txt.trashinfo"); again != id {
+		t.Fatalf("expected the same id every time, got %s and %s", id, again)
+	}
+	if other := trashID("/mnt/usb/.Trash-1000", "a.txt.trashinfo"); other == id {
+		t.Fatalf("expected a different id for the same name in another trash, got %s", other)
+	}
+	if len(id) < ShortIDLength {
+		t.Fatalf("expected an id at least %d long, got %s", ShortIDLength, id)
+	}
+}
+
+func TestByID(t *testing.T) {
+	var (
+		a   = TrashInfo{name: "a.txt", id: "abc1234aaaa"}
+		b   = TrashInfo{name: "b.txt", id: "abc1299bbbb"}
+		c   = TrashInfo{name: "c.txt", id: "fff0000cccc"}
+		fls = Files{a, b, c}
+	)
+
+	got, err := ByID(fls, "@FFF", "abc129")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name() != "c.txt" || got[1].Name() != "b.txt" {
+		t.Fatalf("expected c.txt and b.txt, in the order asked for, got %v", got)
+	}
+
+	_, err = ByID(fls, "@abc12")
+	if err == nil {
+		t.Fatal("expected a prefix of two ids to be ambiguous")
+	}
+	for _, want := range []string{"@abc1234 a.txt", "@abc1299 b.txt"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected the ambiguous error to list '%s', got '%s'", want, err)
+		}
+	}
+
+	if _, err = ByID(fls, "@0123"); err == nil {
+		t.Fatal("expected an error for an id nothing has")
+	}
+}
+
+func TestIsID(t *testing.T) {
+	for arg, want := range map[string]bool{"@abc": true, "@": false, "abc": false, "a@b": false} {
+		if got := IsID(arg); got != want {
+			t.Fatalf("expected IsID(%q) to be %t", arg, want)
+		}
+	}
+}
+
+func TestByIDEntries(t *testing.T) {
+	var (
+		dir    = TrashInfo{name: "dir", ogpath: "/home/user/dir", id: "abc1234aaaa", isdir: true}
+		inside = TrashEntry{name: "inside", ogpath: "/home/user/dir/inside", trash: dir}
+	)
+
+	// files found inside a trashed directory go by its id
+	got, err := ByID(Files{dir, inside}, "@abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].Path() != inside.ogpath {
+		t.Fatalf("expected the directory and what's in it, got %v", got)
+	}
+	if id := idOf(inside); id != "@abc1234" {
+		t.Fatalf("expected what's inside to have its directory's id, got %s", id)
+	}
+}
+
+func TestShortID(t *testing.T) {
+	if got := (TrashInfo{id: "0123456789abcdef"}).ShortID(); got != "0123456" {
+		t.Fatalf("expected the first %d of the id, got %s", ShortIDLength, got)
+	}
+	// ids are only this short in files made up by hand, but they shouldn't break anything
+	for _, id := range []string{"012", ""} {
+		if got := (TrashInfo{id: id}).ShortID(); got != id {
+			t.Fatalf("expected a short id to be all of it, got '%s'", got)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package files
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"gopkg.in/ini.v1"
+)
+
+const (
+	trashInfoMode     string = "X-GT-Mode"
+	trashInfoOwner    string = "X-GT-Owner"
+	trashInfoModified string = "X-GT-Modified"
+	trashInfoParents  string = "X-GT-Parents"
+	ownerSep          string = ":"
+	parentSep         string = ","
+)
+
+// metadata is what a file, or one of its parent directories, was like before it was trashed.
+type metadata struct {
+	mode     fs.FileMode
+	uid, gid int
+	owned    bool
+	modified time.Time
+	// parents are the file's parent directories, nearest first
+	parents []metadata
+}
+
+// recordMetadata reads the metadata of path and its parent directories.
+func recordMetadata(path string) (meta metadata) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return
+	}
+	meta = statMetadata(info)
+
+	for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		info, err := os.Lstat(dir)
+		if err != nil {
+			break
+		}
+		meta.parents = append(meta.parents, statMetadata(info))
+	}
+
+	return
+}
+
+func statMetadata(info fs.FileInfo) metadata {
+	meta := metadata{mode: info.Mode(), modified: info.ModTime()}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		meta.uid, meta.gid, meta.owned = int(stat.Uid), int(stat.Gid), true
+	}
+	return meta
+}
+
+// infoKeys returns the keys to record meta in a trashinfo file.
+func (meta metadata) infoKeys() []infoKey {
+	if meta.mode == 0 && meta.modified.IsZero() {
+		return nil
+	}
+
+	parents := make([]string, 0, len(meta.parents))
+	for _, parent := range meta.parents {
+		parents = append(parents, formatMode(parent.mode)+ownerSep+parent.owner())
+	}
+
+	return []infoKey{
+		{trashInfoMode, formatMode(meta.mode)},
+		{trashInfoOwner, meta.owner()},
+		{trashInfoModified, meta.modified.Format(time.RFC3339Nano)},
+		{trashInfoParents, strings.Join(parents, parentSep)},
+	}
+}
+
+func (meta metadata) owner() string {
+	if !meta.owned {
+		return ""
+	}
+	return strconv.Itoa(meta.uid) + ownerSep + strconv.Itoa(meta.gid)
+}
+
+// readMetadata reads metadata recorded in a trashinfo file's section. Anything
+// missing or unreadable is left blank, and so won't be reapplied.
+func readMetadata(section *ini.Section) (meta metadata) {
+	meta.mode = parseMode(section.Key(trashInfoMode).String())
+	meta.uid, meta.gid, meta.owned = parseOwner(section.Key(trashInfoOwner).String())
+	meta.modified, _ = time.Parse(time.RFC3339Nano, section.Key(trashInfoModified).String())
+
+	if parents := section.Key(trashInfoParents).String(); parents != "" {
+		for _, parent := range strings.Split(parents, parentSep) {
+			var p metadata
+			mode, owner, _ := strings.Cut(parent, ownerSep)
+			p.mode = parseMode(mode)
+			p.uid, p.gid, p.owned = parseOwner(owner)
+			meta.parents = append(meta.parents, p)
+		}
+	}
+
+	return
+}
+
+// apply puts the recorded mode, modification time, and owner if running as root, back on path.
+func (meta metadata) apply(path string) error {
+	if meta.owned && os.Geteuid() == 0 {
+		if err := os.Lchown(path, meta.uid, meta.gid); err != nil {
+			return err
+		}
+	}
+
+	// symlinks have no mode of their own, and Chtimes would follow them
+	if info, err := os.Lstat(path); err != nil || info.Mode()&fs.ModeSymlink != 0 || meta.mode == 0 {
+		return err
+	}
+
+	if err := os.Chmod(path, meta.mode); err != nil {
+		return err
+	}
+	if !meta.modified.IsZero() {
+		return os.Chtimes(path, meta.modified, meta.modified)
+	}
+	return nil
+}
+
+// makeParents creates dir and any of its missing parents with executePerm, and returns
+// the ones it created, nearest first, for applyParents once what's restored is in them.
+func makeParents(dir string) (created []string, err error) {
+	var missing []string
+	for ; dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		log.Infof("creating missing directory %s", missing[i])
+		if err := os.Mkdir(missing[i], executePerm); err != nil {
+			return created, err
+		}
+		created = append([]string{missing[i]}, created...)
+	}
+
+	return created, nil
+}
+
+// removeCreated removes the directories makeParents created, nearest first, after
+// restoring into them failed.
+func removeCreated(created []string) {
+	for _, dir := range created {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
+// applyParents puts the mode and owner recorded in parents, nearest first, on the
+// directories makeParents created, deepest first, so none of them stop the others
+// being changed.
+func applyParents(created []string, parents []metadata) error {
+	for i, dir := range created {
+		if i >= len(parents) {
+			break
+		}
+		// the directory is new, so its time doesn't matter
+		parent := parents[i]
+		parent.modified = time.Time{}
+		if err := parent.apply(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatMode formats md's permissions, and setuid, setgid and sticky bits, as octal.
+func formatMode(md fs.FileMode) string {
+	unix := uint32(md.Perm())
+	if md&fs.ModeSetuid != 0 {
+		unix |= syscall.S_ISUID
+	}
+	if md&fs.ModeSetgid != 0 {
+		unix |= syscall.S_ISGID
+	}
+	if md&fs.ModeSticky != 0 {
+		unix |= syscall.S_ISVTX
+	}
+	return fmt.Sprintf("%04o", unix)
+}
+
+// parseMode parses an octal mode from formatMode, or returns 0 if it can't.
+func parseMode(input string) fs.FileMode {
+	unix, err := strconv.ParseUint(input, 8, 32)
+	if err != nil {
+		return 0
+	}
+
+	md := fs.FileMode(unix).Perm()
+	if unix&syscall.S_ISUID != 0 {
+		md |= fs.ModeSetuid
+	}
+	if unix&syscall.S_ISGID != 0 {
+		md |= fs.ModeSetgid
+	}
+	if unix&syscall.S_ISVTX != 0 {
+		md |= fs.ModeSticky
+	}
+	return md
+}
+
+func parseOwner(input string) (uid, gid int, ok bool) {
+	u, g, found := strings.Cut(input, ownerSep)
+	if !found {
+		return 0, 0, false
+	}
+
+	uid, uerr := strconv.Atoi(u)
+	gid, gerr := strconv.Atoi(g)
+	return uid, gid, uerr == nil && gerr == nil
+}
@@ -0,0 +1,155 @@
+package files
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestFormatMode(t *testing.T) {
+	tests := []struct {
+		mode fs.FileMode
+		want string
+	}{
+		{0o644, "0644"},
+		{fs.ModeDir | 0o755, "0755"},
+		{fs.ModeSetuid | 0o755, "4755"},
+		{fs.ModeSetgid | 0o750, "2750"},
+		{fs.ModeSticky | 0o777, "1777"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			got := formatMode(test.mode)
+			if got != test.want {
+				t.Fatalf("expected '%s', got '%s'", test.want, got)
+			}
+			if back := parseMode(got); back != test.mode&^fs.ModeDir {
+				t.Fatalf("expected '%s' to parse back to %s, got %s", got, test.mode&^fs.ModeDir, back)
+			}
+		})
+	}
+}
+
+func TestParseModeBad(t *testing.T) {
+	for _, input := range []string{"", "rwx", "0999"} {
+		if got := parseMode(input); got != 0 {
+			t.Fatalf("expected '%s' not to parse, got %s", input, got)
+		}
+	}
+}
+
+func TestReadMetadata(t *testing.T) {
+	modified := time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC)
+	meta := metadata{
+		mode:     0o640,
+		uid:      1000,
+		gid:      100,
+		owned:    true,
+		modified: modified,
+		parents:  []metadata{{mode: 0o700, uid: 1000, gid: 100, owned: true}, {mode: 0o755}},
+	}
+
+	file := ini.Empty()
+	section := file.Section(trashInfoSec)
+	for _, key := range meta.infoKeys() {
+		section.Key(key.name).SetValue(key.value)
+	}
+
+	got := readMetadata(section)
+	if got.mode != meta.mode || got.owner() != meta.owner() || !got.modified.Equal(modified) {
+		t.Fatalf("expected %+v, got %+v", meta, got)
+	}
+	if len(got.parents) != 2 || got.parents[0].mode != 0o700 || got.parents[0].owner() != "1000:100" ||
+		got.parents[1].mode != 0o755 || got.parents[1].owned {
+		t.Fatalf("expected parents %+v, got %+v", meta.parents, got.parents)
+	}
+
+	if empty := readMetadata(ini.Empty().Section(trashInfoSec)); empty.mode != 0 || empty.owned || len(empty.parents) != 0 {
+		t.Fatalf("expected nothing from an empty section, got %+v", empty)
+	}
+}
+
+func TestApply(t *testing.T) {
+	var (
+		path     = filepath.Join(t.TempDir(), "file")
+		modified = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	)
+	write(t, path, "")
+
+	if err := (metadata{mode: 0o600, modified: modified}).apply(path); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 || !info.ModTime().Equal(modified) {
+		t.Fatalf("expected 0600 modified %s, got %s modified %s", modified, info.Mode(), info.ModTime())
+	}
+}
+
+func TestRestoreParents(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		a      = filepath.Join(dir, "a")
+		b      = filepath.Join(a, "b")
+		dest   = filepath.Join(b, "file")
+		file   = trashed(t, dir, dest, "trashed")
+		parent = []metadata{{mode: 0o500}, {mode: 0o555}}
+	)
+	file.meta = metadata{mode: 0o644, parents: parent}
+	t.Cleanup(func() {
+		_ = os.Chmod(a, 0o755)
+		_ = os.Chmod(b, 0o755)
+	})
+
+	if _, err := restore(Files{file}, RestoreOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := read(t, dest); got != "trashed" {
+		t.Fatalf("expected the file restored, got '%s'", got)
+	}
+	for path, want := range map[string]fs.FileMode{a: 0o555, b: 0o500} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Fatalf("expected %s to be %s, got %s", path, want, info.Mode().Perm())
+		}
+	}
+}
+
+func TestRestoreMergeMetadata(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		dest = filepath.Join(dir, "d")
+		file = trashed(t, dir, filepath.Join(dest, "inside"), "trashed")
+	)
+	// trash the directory the file was written into instead of the file
+	file.name, file.path, file.ogpath, file.isdir = "d", filepath.Dir(file.path), dest, true
+	file.meta = metadata{mode: fs.ModeDir | 0o700}
+	write(t, filepath.Join(dest, "existing"), "existing")
+
+	if _, err := restore(Files{file}, RestoreOptions{OnConflict: Merge}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := read(t, filepath.Join(dest, "inside")); got != "trashed" {
+		t.Fatalf("expected the trashed file merged in, got '%s'", got)
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o700 {
+		t.Fatalf("expected the merged directory to get its recorded mode, got %s", info.Mode().Perm())
+	}
+}
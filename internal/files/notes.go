@@ -0,0 +1,156 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"git.burning.moe/celediel/gt/internal/prompt"
+)
+
+const (
+	trashInfoNote string = "X-GT-Note"
+	trashInfoTags string = "X-GT-Tags"
+	tagSep        string = ","
+)
+
+// TrashOptions is extra info recorded in the trashinfo files of trashed files.
+type TrashOptions struct {
+	Note string
+	Tags []string
+}
+
+// TagOptions changes the note and tags of files already in the trash.
+type TagOptions struct {
+	Add, Remove []string
+	// Note replaces the note, if it isn't empty
+	Note      string
+	ClearNote bool
+}
+
+// ConfirmTag changes the notes and tags of fs, if confirmed.
+func ConfirmTag(confirm bool, fs Files, opts TagOptions) error {
+	if len(opts.Add) == 0 && len(opts.Remove) == 0 && opts.Note == "" && !opts.ClearNote {
+		return fmt.Errorf("nothing to do, give tags to --add or --remove, or a --note")
+	}
+
+	if confirm {
+		yes, err := prompt.YesNo(fmt.Sprintf("change tags of %d selected files?", len(fs)))
+		if err != nil {
+			return err
+		}
+		if !yes {
+			fmt.Fprintf(os.Stdout, "not doing anything\n")
+			return nil
+		}
+	}
+
+	var (
+		tagged int
+		remove = cleanTags(opts.Remove)
+	)
+	for _, maybeFile := range fs {
+		file, ok := maybeFile.(TrashInfo)
+		if !ok {
+			return fmt.Errorf("bad file?? %s", maybeFile.Name())
+		}
+
+		note := file.note
+		if opts.ClearNote {
+			note = ""
+		}
+		if opts.Note != "" {
+			note = opts.Note
+		}
+
+		var tags []string
+		for _, tag := range append(file.tags, cleanTags(opts.Add)...) {
+			if !slices.Contains(remove, tag) && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+
+		if err := writeNote(file.trashinfo, note, tags); err != nil {
+			return fmt.Errorf("tagged %d files before error %w", tagged, err)
+		}
+		tagged++
+	}
+
+	fmt.Fprintf(os.Stdout, "tagged %d files\n", tagged)
+	return nil
+}
+
+// writeNote replaces the note and tags in the trashinfo file at path.
+func writeNote(path, note string, tags []string) error {
+	return writeInfoKeys(path, noteKeys(note, tags)...)
+}
+
+func noteKeys(note string, tags []string) []infoKey {
+	return []infoKey{
+		{trashInfoNote, strings.Join(strings.Fields(note), " ")},
+		{trashInfoTags, strings.Join(tags, tagSep)},
+	}
+}
+
+// infoKey is an extra key for a trashinfo file. Keys with no value are removed.
+type infoKey struct {
+	name, value string
+}
+
+// writeInfoKeys sets keys in the trashinfo file at path. Other lines are left as
+// they are, so tools that don't know about the keys can still read the file.
+func writeInfoKeys(path string, keys ...infoKey) error {
+	info, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var (
+		lines   []string
+		section = -1
+		end     = -1
+	)
+	for _, line := range strings.Split(strings.TrimRight(string(info), "\n"), "\n") {
+		switch {
+		case slices.ContainsFunc(keys, func(key infoKey) bool { return strings.HasPrefix(line, key.name+"=") }):
+			continue
+		case strings.TrimSpace(line) == "["+trashInfoSec+"]":
+			section = len(lines)
+		case strings.HasPrefix(line, "[") && section >= 0 && end < 0:
+			end = len(lines)
+		}
+		lines = append(lines, line)
+	}
+	if section < 0 {
+		return fmt.Errorf("%s has no [%s] section", path, trashInfoSec)
+	}
+	if end < 0 {
+		end = len(lines)
+	}
+	for end > section+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	var set []string
+	for _, key := range keys {
+		if key.value != "" {
+			set = append(set, key.name+"="+key.value)
+		}
+	}
+	lines = slices.Insert(lines, end, set...)
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), noExecuteUserPerm)
+}
+
+// cleanTags trims tags, and splits any with commas in them, since that's what separates them.
+func cleanTags(tags []string) (out []string) {
+	for _, tag := range tags {
+		for _, t := range strings.Split(tag, tagSep) {
+			if t = strings.TrimSpace(t); t != "" && !slices.Contains(out, t) {
+				out = append(out, t)
+			}
+		}
+	}
+	return
+}
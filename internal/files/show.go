@@ -0,0 +1,206 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"git.burning.moe/celediel/gt/internal/diff"
+	"git.burning.moe/celediel/gt/internal/dirs"
+)
+
+const (
+	diffContext int    = 3
+	devNull     string = "/dev/null"
+)
+
+// Cat writes the contents of a trashed file, or a file inside a trashed directory, to w.
+func Cat(file File, w io.Writer) error {
+	if file.IsDir() {
+		return fmt.Errorf("%s is a directory", file.Name())
+	}
+
+	f, err := os.Open(Location(file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// Diff writes how a trashed file differs from what's at its original path now to w, as a
+// unified diff, or for directories, which files were added, removed, or changed since.
+func Diff(file File, w io.Writer) error {
+	var (
+		trashed = Location(file)
+		current = dirs.PercentDecode(file.Path())
+	)
+
+	info, err := os.Lstat(current)
+	switch {
+	case os.IsNotExist(err):
+		if file.IsDir() {
+			fmt.Fprintf(w, "%s doesn't exist any more\n", current)
+			return nil
+		}
+		return diffFiles(w, trashed, devNull)
+	case err != nil:
+		return err
+	case file.IsDir() != info.IsDir():
+		fmt.Fprintf(w, "%s is a %s now\n", current, kind(info.IsDir()))
+		return nil
+	case file.IsDir():
+		return diffDirs(w, trashed, current)
+	default:
+		return diffFiles(w, trashed, current)
+	}
+}
+
+// diffFiles writes a unified diff of the files at a and b to w.
+func diffFiles(w io.Writer, a, b string) error {
+	atext, err := os.ReadFile(a)
+	if err != nil {
+		return err
+	}
+
+	var btext []byte
+	if b != devNull {
+		if btext, err = os.ReadFile(b); err != nil {
+			return err
+		}
+	}
+
+	if IsBinary(atext) || IsBinary(btext) {
+		if !bytes.Equal(atext, btext) {
+			fmt.Fprintf(w, "Binary files %s and %s differ\n", a, b)
+		}
+		return nil
+	}
+
+	unified, err := diff.Unified(a, b, diff.Lines(string(atext)), diff.Lines(string(btext)), diffContext)
+	if errors.Is(err, diff.ErrTooDifferent) {
+		fmt.Fprintf(w, "Files %s and %s differ\n", a, b)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, unified)
+	return err
+}
+
+// diffDirs writes which files under the directory b were added, removed, or changed, compared to a.
+func diffDirs(w io.Writer, a, b string) error {
+	afiles, err := walkFiles(a)
+	if err != nil {
+		return err
+	}
+	bfiles, err := walkFiles(b)
+	if err != nil {
+		return err
+	}
+
+	var rels []string
+	for rel := range afiles {
+		rels = append(rels, rel)
+	}
+	for rel := range bfiles {
+		if _, ok := afiles[rel]; !ok {
+			rels = append(rels, rel)
+		}
+	}
+	slices.Sort(rels)
+
+	for _, rel := range rels {
+		ainfo, inA := afiles[rel]
+		binfo, inB := bfiles[rel]
+		switch {
+		case !inB:
+			fmt.Fprintf(w, "removed\t%s\n", rel)
+		case !inA:
+			fmt.Fprintf(w, "added\t%s\n", rel)
+		case ainfo.IsDir() != binfo.IsDir():
+			fmt.Fprintf(w, "changed\t%s (is a %s now)\n", rel, kind(binfo.IsDir()))
+		case !ainfo.IsDir() && !sameContents(filepath.Join(a, rel), filepath.Join(b, rel), ainfo, binfo):
+			fmt.Fprintf(w, "changed\t%s\n", rel)
+		}
+	}
+
+	return nil
+}
+
+// walkFiles returns everything under dir, by path relative to it.
+func walkFiles(dir string) (map[string]fs.FileInfo, error) {
+	found := map[string]fs.FileInfo{}
+	err := filepath.WalkDir(dir, func(path string, dirEntry fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		found[rel] = info
+		return nil
+	})
+	return found, err
+}
+
+func sameContents(a, b string, ainfo, binfo fs.FileInfo) bool {
+	if ainfo.Size() != binfo.Size() || ainfo.Mode().Type() != binfo.Mode().Type() {
+		return false
+	}
+
+	if ainfo.Mode()&fs.ModeSymlink != 0 {
+		alink, aerr := os.Readlink(a)
+		blink, berr := os.Readlink(b)
+		return aerr == nil && berr == nil && alink == blink
+	}
+
+	atext, aerr := os.ReadFile(a)
+	btext, berr := os.ReadFile(b)
+	return aerr == nil && berr == nil && bytes.Equal(atext, btext)
+}
+
+// IsBinary checks for a NUL byte near the start of text, like git does.
+func IsBinary(text []byte) bool {
+	return bytes.IndexByte(text[:min(len(text), binaryCheckLen)], 0) >= 0
+}
+
+func kind(isdir bool) string {
+	if isdir {
+		return "directory"
+	}
+	return "file"
+}
+
+// Ambiguous checks if any of fls were trashed from the same path, for commands that
+// should only operate on one version of each, and returns an error listing them if so.
+func Ambiguous(fls Files) error {
+	for path, versions := range Versions(fls) {
+		if len(versions) < 2 {
+			continue
+		}
+
+		var ids []string
+		for _, version := range versions {
+			if t, ok := version.(TrashInfo); ok {
+				ids = append(ids, IDPrefix+t.ShortID())
+			}
+		}
+		return fmt.Errorf("%s was trashed %d times, pick one by id: %s", path, len(versions), strings.Join(ids, ", "))
+	}
+	return nil
+}
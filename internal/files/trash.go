@@ -45,6 +45,10 @@ var homeTrash = filepath.Join(xdg.DataHome, "Trash")
 type TrashInfo struct {
 	name, ogpath    string
 	path, trashinfo string
+	id              string
+	note            string
+	tags            []string
+	meta            metadata
 	isdir           bool
 	trashed         time.Time
 	filesize        int64
@@ -59,6 +63,10 @@ func (t TrashInfo) Date() time.Time   { return t.trashed }
 func (t TrashInfo) IsDir() bool       { return t.isdir }
 func (t TrashInfo) Mode() fs.FileMode { return t.mode }
 func (t TrashInfo) Filesize() int64   { return t.filesize }
+func (t TrashInfo) ID() string        { return t.id }
+func (t TrashInfo) ShortID() string   { return t.id[:min(len(t.id), ShortIDLength)] }
+func (t TrashInfo) Note() string      { return t.note }
+func (t TrashInfo) Tags() []string    { return t.tags }
 
 func (t TrashInfo) String() string {
 	return t.name + t.path + t.ogpath + t.trashinfo
@@ -79,14 +87,26 @@ func FindInAllTrashes(ogdir string, fltr *filter.Filter) Files {
 	return files
 }
 
-func ConfirmRestore(confirm bool, fs Files) error {
-	if !confirm || prompt.YesNo(fmt.Sprintf("restore %d selected files?", len(fs))) {
+func ConfirmRestore(confirm bool, fs Files, opts RestoreOptions) error {
+	var (
+		yes = true
+		err error
+	)
+	if confirm {
+		yes, err = prompt.YesNo(fmt.Sprintf("restore %d selected files?", len(fs)))
+		if err != nil {
+			return err
+		}
+	}
+
+	if yes {
 		log.Info("doing the thing")
-		restored, err := restore(fs)
+		// what was done before an error is still worth knowing
+		summary, err := Restore(fs, opts)
+		fmt.Fprintln(os.Stdout, summary)
 		if err != nil {
-			return fmt.Errorf("restored %d files before error %w", restored, err)
+			return err
 		}
-		fmt.Fprintf(os.Stdout, "restored %d files\n", restored)
 	} else {
 		fmt.Fprintf(os.Stdout, "not doing anything\n")
 	}
@@ -94,11 +114,21 @@ func ConfirmRestore(confirm bool, fs Files) error {
 }
 
 func ConfirmClean(confirm bool, fs Files) error {
-	if prompt.YesNo(fmt.Sprintf("remove %d selected files permanently from the trash?", len(fs))) &&
-		(!confirm || prompt.YesNo(fmt.Sprintf("really remove all these %d selected files permanently from the trash forever??", len(fs)))) {
-		removed, err := remove(fs)
+	yes, err := prompt.YesNo(fmt.Sprintf("remove %d selected files permanently from the trash?", len(fs)))
+	if err != nil {
+		return err
+	}
+	if yes && confirm {
+		yes, err = prompt.YesNo(fmt.Sprintf("really remove all these %d selected files permanently from the trash forever??", len(fs)))
 		if err != nil {
-			return fmt.Errorf("removed %d files before error %w", removed, err)
+			return err
+		}
+	}
+
+	if yes {
+		removed, err := Remove(fs)
+		if err != nil {
+			return err
 		}
 		fmt.Fprintf(os.Stdout, "removed %d files\n", removed)
 	} else {
@@ -107,14 +137,43 @@ func ConfirmClean(confirm bool, fs Files) error {
 	return nil
 }
 
-func ConfirmTrash(confirm bool, fs Files) error {
-	if !confirm || prompt.YesNo(fmt.Sprintf("trash %d selected files?", len(fs))) {
+// Restore restores fs without asking first, and returns a summary of what was done.
+func Restore(fs Files, opts RestoreOptions) (string, error) {
+	report, err := restore(fs, opts)
+	if err != nil {
+		return report.String(), fmt.Errorf("restored %d files before error %w", report.restored, err)
+	}
+	return report.String(), nil
+}
+
+// Remove permanently removes fs from the trash without asking first, and returns how many were.
+func Remove(fs Files) (int, error) {
+	removed, err := remove(fs)
+	if err != nil {
+		return removed, fmt.Errorf("removed %d files before error %w", removed, err)
+	}
+	return removed, nil
+}
+
+func ConfirmTrash(confirm bool, fs Files, opts TrashOptions) error {
+	var (
+		yes = true
+		err error
+	)
+	if confirm {
+		yes, err = prompt.YesNo(fmt.Sprintf("trash %d selected files?", len(fs)))
+		if err != nil {
+			return err
+		}
+	}
+
+	if yes {
 		tfs := make([]string, 0, len(fs))
 		for _, file := range fs {
 			tfs = append(tfs, file.Path())
 		}
 
-		trashed := trashFiles(tfs)
+		trashed := trashFiles(tfs, opts)
 
 		var s string
 		if trashed > 1 {
@@ -146,7 +205,7 @@ func findTrash(trashdir, ogdir string, fltr *filter.Filter) (Files, error) {
 		path := filepath.Join(infodir, entry.Name())
 
 		// trashinfo is just an ini file, so
-		trashInfo, err := ini.Load(path)
+		trashInfo, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, path)
 		if err != nil {
 			log.Errorf("error reading %s: %s", path, err)
 			continue
@@ -193,15 +252,23 @@ func findTrash(trashdir, ogdir string, fltr *filter.Filter) (Files, error) {
 			size = info.Size()
 		}
 
-		if fltr.Match(info) {
+		note := section.Key(trashInfoNote).String()
+		tags := cleanTags(strings.Split(section.Key(trashInfoTags).String(), tagSep))
+
+		if fltr.Match(info) && fltr.MatchNote(note, tags) {
 			files = append(files, TrashInfo{
 				name:      filename,
 				path:      trashedpath,
 				ogpath:    basepath,
 				trashinfo: path,
+				id:        trashID(trashdir, entry.Name()),
+				note:      note,
+				tags:      tags,
+				meta:      readMetadata(section),
 				trashed:   date,
 				isdir:     info.IsDir(),
 				filesize:  size,
+				mode:      info.Mode(),
 			})
 		}
 	}
@@ -209,13 +276,19 @@ func findTrash(trashdir, ogdir string, fltr *filter.Filter) (Files, error) {
 	return files, nil
 }
 
-func trashFile(filename string) error {
-	trashDir, err := getTrashDir(filename)
+func trashFile(filename string, opts TrashOptions) error {
+	return trashFileAs(filename, filename, opts)
+}
+
+// trashFileAs trashes filename as if it was trashed from ogpath, in the same directory.
+func trashFileAs(filename, ogpath string, opts TrashOptions) error {
+	trashDir, err := getTrashDir(ogpath)
 	if err != nil {
 		return err
 	}
 
-	trashInfoFilename, outPath := getTrashFilenames(filepath.Base(filename), trashDir)
+	trashInfoFilename, outPath := getTrashFilenames(filepath.Base(ogpath), trashDir)
+	meta := recordMetadata(filename)
 
 	if err := os.Rename(filename, outPath); err != nil {
 		return err
@@ -223,19 +296,19 @@ func trashFile(filename string) error {
 
 	var path string
 	if trashDir == homeTrash {
-		path = filename
+		path = ogpath
 	} else {
 		root, err := getRoot(trashDir)
 		if err != nil {
-			path = filename
+			path = ogpath
 		} else {
-			path = strings.Replace(filename, root+string(os.PathSeparator), "", 1)
+			path = strings.Replace(ogpath, root+string(os.PathSeparator), "", 1)
 		}
 	}
-	log.Debugf("fucking %s %s %s", filename, trashDir, path)
+	log.Debugf("fucking %s %s %s", ogpath, trashDir, path)
 
 	trashInfo, err := formatter.Format(trashInfoTemplate, formatter.Named{
-		"path": path,
+		"path": dirs.PercentEncode(path),
 		"date": time.Now().Format(trashInfoDateFmt),
 	})
 	if err != nil {
@@ -246,12 +319,12 @@ func trashFile(filename string) error {
 		return err
 	}
 
-	return nil
+	return writeInfoKeys(trashInfoFilename, append(meta.infoKeys(), noteKeys(opts.Note, cleanTags(opts.Tags))...)...)
 }
 
-func trashFiles(files []string) (trashed int) {
+func trashFiles(files []string, opts TrashOptions) (trashed int) {
 	for _, file := range files {
-		if err := trashFile(file); err != nil {
+		if err := trashFile(file, opts); err != nil {
 			log.Errorf("cannot trash '%s': %s", file, err)
 			continue
 		}
@@ -260,46 +333,166 @@ func trashFiles(files []string) (trashed int) {
 	return
 }
 
-func restore(files Files) (restored int, err error) {
+func restore(files Files, opts RestoreOptions) (report restoreReport, err error) {
+	base := commonDir(files)
+	if opts.To != "" {
+		if err = os.MkdirAll(opts.To, executePerm); err != nil {
+			return report, err
+		}
+	}
+
 	for _, maybeFile := range files {
-		file, ok := maybeFile.(TrashInfo)
-		if !ok {
-			return restored, fmt.Errorf("bad file?? %s", maybeFile.Name())
+		var (
+			skip                       bool
+			aside                      string
+			name, trashpath, trashinfo string
+			isdir                      bool
+			meta                       metadata
+		)
+		switch file := maybeFile.(type) {
+		case TrashInfo:
+			name, trashpath, trashinfo, isdir, meta = file.name, file.path, file.trashinfo, file.isdir, file.meta
+		case TrashEntry:
+			// only the entry comes out, the directory it was in stays in the trash
+			name, trashpath, isdir, meta = file.name, file.path, file.isdir, file.metadata()
+		default:
+			return report, fmt.Errorf("bad file?? %s", maybeFile.Name())
 		}
 
-		var cancel bool
-		outpath := dirs.PercentDecode(file.ogpath)
-		log.Infof("restoring %s back to %s\n", file.name, outpath)
+		if _, e := os.Lstat(trashpath); os.IsNotExist(e) && trashinfo == "" {
+			log.Infof("%s was already restored along with a directory it was in", name)
+			continue
+		}
+
+		outpath := restorePath(maybeFile, base, opts)
+		log.Infof("restoring %s back to %s\n", name, outpath)
 		if _, e := os.Lstat(outpath); e == nil {
-			outpath, cancel = prompt.NewPath(outpath)
+			outpath, aside, skip, err = resolveConflict(isdir, outpath, opts.OnConflict, opts.RenameTemplate)
+			if err != nil {
+				return report, err
+			}
 		}
 
-		if cancel {
+		if skip {
+			report.skipped++
 			continue
 		}
 
+		// parents are only recreated as they were when restoring to where they were
+		var parents []metadata
 		basedir := filepath.Dir(outpath)
-		if _, e := os.Lstat(basedir); e != nil {
-			if err = os.MkdirAll(basedir, executePerm); err != nil {
-				return restored, err
+		if basedir == filepath.Dir(dirs.PercentDecode(maybeFile.Path())) {
+			parents = meta.parents
+		}
+		var created []string
+		if created, err = makeParents(basedir); err != nil {
+			removeCreated(created)
+			return report, putBack(aside, outpath, err)
+		}
+
+		// only still exists if it's a directory to merge into
+		if _, e := os.Lstat(outpath); e == nil {
+			leftover, err := mergeDir(trashpath, outpath, opts, &report)
+			if err != nil {
+				return report, err
+			}
+			if leftover {
+				// the rest stays in the trash, trashinfo and all
+				log.Infof("couldn't merge all of %s, the rest is still in the trash", name)
+				report.skipped++
+				continue
 			}
+		} else if err = os.Rename(trashpath, outpath); err != nil {
+			removeCreated(created)
+			return report, putBack(aside, outpath, err)
+		} else if err = dropAside(aside, outpath, opts.OnConflict == Backup); err != nil {
+			return report, err
 		}
 
-		if err = os.Rename(file.path, outpath); err != nil {
-			return restored, err
+		if e := meta.apply(outpath); e != nil {
+			report.warnings = append(report.warnings, fmt.Sprintf("couldn't restore mode, owner or time of %s: %s", outpath, e))
+		}
+		if e := applyParents(created, parents); e != nil {
+			report.warnings = append(report.warnings, fmt.Sprintf("couldn't restore mode or owner of the directories %s is in: %s", outpath, e))
 		}
 
-		if err = os.Remove(file.trashinfo); err != nil {
-			return restored, err
+		if trashinfo != "" {
+			if err = os.Remove(trashinfo); err != nil {
+				return report, err
+			}
 		}
 
-		restored++
+		report.restored++
 	}
-	return restored, err
+	return report, err
+}
+
+// Existing returns where fs would be restored to with opts, that something is already at.
+func Existing(fs Files, opts RestoreOptions) []string {
+	var (
+		out  []string
+		base = commonDir(fs)
+	)
+	for _, file := range fs {
+		outpath := restorePath(file, base, opts)
+		if _, err := os.Lstat(outpath); err == nil {
+			out = append(out, outpath)
+		}
+	}
+	return out
+}
+
+// restorePath returns where file should be restored to; where it was trashed from,
+// or opts.To, keeping its path relative to base if opts.KeepStructure is set.
+func restorePath(file File, base string, opts RestoreOptions) string {
+	ogpath := dirs.PercentDecode(file.Path())
+	if opts.To == "" {
+		return ogpath
+	}
+
+	if opts.KeepStructure {
+		if rel, err := filepath.Rel(base, ogpath); err == nil {
+			return filepath.Join(opts.To, rel)
+		}
+	}
+
+	return filepath.Join(opts.To, filepath.Base(ogpath))
+}
+
+// commonDir returns the deepest directory that all of files were trashed from.
+func commonDir(files Files) string {
+	var common []string
+	for i, file := range files {
+		parts := strings.Split(filepath.Dir(dirs.PercentDecode(file.Path())), string(os.PathSeparator))
+		if i == 0 {
+			common = parts
+			continue
+		}
+
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	if len(common) == 1 && common[0] == "" {
+		return string(os.PathSeparator)
+	}
+	return strings.Join(common, string(os.PathSeparator))
 }
 
 func remove(files Files) (removed int, err error) {
 	for _, maybeFile := range files {
+		if entry, ok := maybeFile.(TrashEntry); ok {
+			// just this part of a trashed directory
+			if err = os.RemoveAll(entry.path); err != nil {
+				return removed, err
+			}
+			removed++
+			continue
+		}
+
 		file, ok := maybeFile.(TrashInfo)
 		if !ok {
 			return removed, fmt.Errorf("bad file?? %s", maybeFile.Name())
@@ -0,0 +1,88 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// trashed puts content in a fake trash under dir, as if it was trashed from ogpath.
+func trashed(t *testing.T, dir, ogpath, content string) TrashInfo {
+	t.Helper()
+	var (
+		name = filepath.Base(ogpath)
+		path = filepath.Join(dir, "trash", "files", name)
+		info = filepath.Join(dir, "trash", "info", name+trashInfoExt)
+	)
+	write(t, path, content)
+	write(t, info, "[Trash Info]\n")
+	return TrashInfo{name: name, path: path, ogpath: ogpath, trashinfo: info}
+}
+
+func TestRestoreTo(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		var (
+			dir  = t.TempDir()
+			out  = filepath.Join(dir, "out")
+			proj = filepath.Join(dir, "proj")
+			fls  = Files{
+				trashed(t, dir, filepath.Join(proj, "a.txt"), "a"),
+				trashed(t, dir, filepath.Join(proj, "src", "b.go"), "b"),
+			}
+		)
+
+		if _, err := restore(fls, RestoreOptions{To: out, KeepStructure: keep}); err != nil {
+			t.Fatal(err)
+		}
+
+		b := filepath.Join(out, "b.go")
+		if keep {
+			// laid out as they were under proj, the directory they have in common
+			b = filepath.Join(out, "src", "b.go")
+		}
+		if got := read(t, filepath.Join(out, "a.txt")) + read(t, b); got != "ab" {
+			t.Fatalf("expected both restored under %s, got '%s'", out, got)
+		}
+		if _, err := os.Lstat(proj); !os.IsNotExist(err) {
+			t.Fatalf("expected nothing restored to where it was trashed from, got %v", err)
+		}
+		if _, err := os.Lstat(fls[0].(TrashInfo).trashinfo); !os.IsNotExist(err) {
+			t.Fatalf("expected the trashinfo file to be removed, got %v", err)
+		}
+	}
+}
+
+func TestCommonDir(t *testing.T) {
+	fls := Files{
+		TrashInfo{ogpath: "/home/user/src/a.go"},
+		TrashInfo{ogpath: "/home/user/src/sub/b.go"},
+		TrashInfo{ogpath: "/home/user/srcs/c.go"},
+	}
+	if got := commonDir(fls); got != "/home/user" {
+		t.Fatalf("expected /home/user, not a directory partly named the same, got %s", got)
+	}
+
+	fls = append(fls, TrashInfo{ogpath: "/etc/d"})
+	if got := commonDir(fls); got != "/" {
+		t.Fatalf("expected only / in common, got %s", got)
+	}
+}
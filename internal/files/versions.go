@@ -0,0 +1,169 @@
+package files
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.burning.moe/celediel/gt/internal/dirs"
+	"git.burning.moe/celediel/gt/internal/prompt"
+)
+
+// AsOf returns the files that were trashed from dir or anywhere under it after t. When the
+// same path was trashed more than once, only the earliest is returned, as that is the one
+// that was there at t. Parents are sorted before their children.
+func AsOf(fls Files, dir string, t time.Time) Files {
+	var (
+		out      Files
+		earliest = map[string]File{}
+	)
+
+	dir = filepath.Clean(dir)
+	for _, file := range fls {
+		path := dirs.PercentDecode(file.Path())
+		if !isUnder(dir, path) || !file.Date().After(t) {
+			continue
+		}
+
+		if e, ok := earliest[path]; !ok || compareVersions(file, e) < 0 {
+			earliest[path] = file
+		}
+	}
+
+	for _, file := range earliest {
+		out = append(out, file)
+	}
+	slices.SortFunc(out, func(a, b File) int {
+		return cmp.Compare(a.Path(), b.Path())
+	})
+
+	return out
+}
+
+// Versions groups files by the path they were trashed from, each sorted oldest first.
+func Versions(fls Files) map[string]Files {
+	versions := map[string]Files{}
+	for _, file := range fls {
+		path := dirs.PercentDecode(file.Path())
+		versions[path] = append(versions[path], file)
+	}
+
+	for _, v := range versions {
+		slices.SortStableFunc(v, compareVersions)
+	}
+
+	return versions
+}
+
+// compareVersions orders versions of a path oldest first. Deletion dates are only to the
+// second, so ones trashed in the same second go by when their trashinfo files were
+// written, then by their names.
+func compareVersions(a, b File) int {
+	if c := a.Date().Compare(b.Date()); c != 0 {
+		return c
+	}
+
+	ainfo, binfo := trashOf(a).trashinfo, trashOf(b).trashinfo
+	return cmp.Or(infoTime(ainfo).Compare(infoTime(binfo)), cmp.Compare(ainfo, binfo))
+}
+
+// infoTime is when the trashinfo file at path was last written, or the zero time if it can't be read.
+func infoTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// PickVersion returns the version of a file described by which: "oldest", "latest",
+// or a number counting from 1 for the oldest.
+func PickVersion(versions Files, which string) (File, error) {
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions to pick from")
+	}
+
+	switch which {
+	case "oldest":
+		return versions[0], nil
+	case "latest":
+		return versions[len(versions)-1], nil
+	}
+
+	n, err := strconv.Atoi(which)
+	if err != nil {
+		return nil, fmt.Errorf("bad version '%s' (possible values: a number, latest, oldest)", which)
+	}
+	if n < 1 || n > len(versions) {
+		return nil, fmt.Errorf("no version %d of %s, there are %d", n, versions[0].Path(), len(versions))
+	}
+
+	return versions[n-1], nil
+}
+
+// VersionsString lists each path and its versions, numbered oldest first, with their
+// ids last.
+func VersionsString(fls Files) string {
+	var (
+		out      = strings.Builder{}
+		versions = Versions(fls)
+		paths    = make([]string, 0, len(versions))
+	)
+
+	for path := range versions {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		out.WriteString(path + "\n")
+		for i, file := range versions[path] {
+			trashed := file.Name()
+			if t, ok := file.(TrashInfo); ok {
+				trashed = filepath.Base(t.path)
+			}
+			out.WriteString(fmt.Sprintf("\t%d\t%s\t%s\t%s\n", i+1, file.Date().Format(time.RFC3339), trashed, idOf(file)))
+		}
+	}
+
+	return out.String()
+}
+
+// ConfirmRestorePlan lists what is going to be restored and where to, and restores it if confirmed.
+func ConfirmRestorePlan(fs Files, opts RestoreOptions) error {
+	base := commonDir(fs)
+	for _, maybeFile := range fs {
+		file, ok := maybeFile.(TrashInfo)
+		if !ok {
+			return fmt.Errorf("bad file?? %s", maybeFile.Name())
+		}
+		fmt.Fprintf(os.Stdout, "%s%s\t%s\t%s\n", IDPrefix, file.ShortID(), file.Date().Format(time.RFC3339), restorePath(file, base, opts))
+	}
+
+	yes, err := prompt.YesNo(fmt.Sprintf("restore these %d files?", len(fs)))
+	if err != nil {
+		return err
+	}
+	if !yes {
+		fmt.Fprintf(os.Stdout, "not doing anything\n")
+		return nil
+	}
+
+	return ConfirmRestore(false, fs, opts)
+}
+
+// isUnder checks if path is dir or anywhere under it.
+func isUnder(dir, path string) bool {
+	if dir == string(os.PathSeparator) {
+		return true
+	}
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
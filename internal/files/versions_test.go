@@ -0,0 +1,75 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAsOf(t *testing.T) {
+	var (
+		then = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		at   = func(path string, hours time.Duration) TrashInfo {
+			return TrashInfo{ogpath: path, trashed: then.Add(hours * time.Hour)}
+		}
+
+		before    = at("/p/old.go", -1)
+		firstMain = at("/p/main.go", 1)
+		lastMain  = at("/p/main.go", 3)
+		dir       = at("/p", 2)
+		sibling   = at("/proj/other.go", 1)
+	)
+
+	got := AsOf(Files{lastMain, before, sibling, firstMain, dir}, "/p/", then)
+
+	// the directory comes back before what was in it, and main.go as it was at the
+	// time, the first time it was trashed afterwards
+	want := Files{dir, firstMain}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i].Path() != want[i].Path() || !got[i].Date().Equal(want[i].Date()) {
+			t.Fatalf("expected %s trashed at %s, got %s trashed at %s",
+				want[i].Path(), want[i].Date(), got[i].Path(), got[i].Date())
+		}
+	}
+
+	if got := AsOf(Files{before}, "/p", then); len(got) != 0 {
+		t.Fatalf("expected nothing trashed after %s, got %v", then, got)
+	}
+}
+
+func TestVersionsSameSecond(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		then    = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		first   = trashed(t, dir, "/p/main.go", "first")
+		second  = trashed(t, filepath.Join(dir, "again"), "/p/main.go", "second")
+		written = time.Now()
+	)
+	// trashed in the same second, which is as close as trashinfo dates go
+	first.trashed, second.trashed = then, then
+	for i, info := range []string{first.trashinfo, second.trashinfo} {
+		at := written.Add(time.Duration(i) * time.Millisecond)
+		if err := os.Chtimes(info, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, fls := range []Files{{first, second}, {second, first}} {
+		versions := Versions(fls)["/p/main.go"]
+		latest, err := PickVersion(versions, "latest")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if versions[0].(TrashInfo).path != first.path || latest.(TrashInfo).path != second.path {
+			t.Fatalf("expected the one trashed first to be the oldest, got %v", versions)
+		}
+
+		if got := AsOf(fls, "/p", then.Add(-time.Hour)); len(got) != 1 || got[0].(TrashInfo).path != first.path {
+			t.Fatalf("expected the first trashed to be how it was, got %v", got)
+		}
+	}
+}
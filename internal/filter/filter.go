@@ -20,10 +20,12 @@ type Filter struct {
 	glob, pattern       string
 	unglob, unpattern   string
 	filenames           []string
+	tags                []string
 	dirsonly, filesonly bool
 	ignorehidden        bool
 	matcher             *regexp.Regexp
 	unmatcher           *regexp.Regexp
+	notematcher         *regexp.Regexp
 	minsize, maxsize    int64
 	mode                fs.FileMode
 }
@@ -34,6 +36,7 @@ func (f *Filter) Before() time.Time   { return f.before }
 func (f *Filter) Glob() string        { return f.glob }
 func (f *Filter) Pattern() string     { return f.pattern }
 func (f *Filter) FileNames() []string { return f.filenames }
+func (f *Filter) Tags() []string      { return f.tags }
 func (f *Filter) FilesOnly() bool     { return f.filesonly }
 func (f *Filter) DirsOnly() bool      { return f.dirsonly }
 func (f *Filter) IgnoreHidden() bool  { return f.ignorehidden }
@@ -52,6 +55,39 @@ func (f *Filter) AddFileNames(filenames ...string) {
 	}
 }
 
+// AddTags adds tags that files must all have to match.
+func (f *Filter) AddTags(tags ...string) {
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(f.tags, tag) {
+			f.tags = append(f.tags, tag)
+		}
+	}
+}
+
+// SetNoteMatch sets a pattern that files' notes must match.
+func (f *Filter) SetNoteMatch(pattern string) error {
+	var err error
+	f.notematcher, err = regexp.Compile(pattern)
+	return err
+}
+
+// MatchNote checks a trashed file's note and tags against the filter.
+func (f *Filter) MatchNote(note string, tags []string) bool {
+	for _, tag := range f.tags {
+		if !slices.Contains(tags, tag) {
+			log.Debugf("%v doesn't have tag %s, bye!", tags, tag)
+			return false
+		}
+	}
+
+	if f.hasNoteRegex() && !f.notematcher.MatchString(note) {
+		log.Debugf("note '%s' doesn't match `%s`, bye!", note, f.notematcher.String())
+		return false
+	}
+
+	return true
+}
+
 func (f *Filter) Match(info fs.FileInfo) bool {
 	filename := info.Name()
 	modified := info.ModTime()
@@ -164,6 +200,8 @@ func (f *Filter) Blank() bool {
 		f.before.Equal(blank) &&
 		f.on.Equal(blank) &&
 		len(f.filenames) == 0 &&
+		len(f.tags) == 0 &&
+		!f.hasNoteRegex() &&
 		!f.ignorehidden &&
 		!f.filesonly &&
 		!f.dirsonly &&
@@ -173,20 +211,24 @@ func (f *Filter) Blank() bool {
 }
 
 func (f *Filter) String() string {
-	var match, unmatch string
+	var match, unmatch, notematch string
 	if f.matcher != nil {
 		match = f.matcher.String()
 	}
 	if f.unmatcher != nil {
 		unmatch = f.unmatcher.String()
 	}
+	if f.notematcher != nil {
+		notematch = f.notematcher.String()
+	}
 	return fmt.Sprintf("on:'%s' before:'%s' after:'%s' glob:'%s' regex:'%s' unglob:'%s' "+
 		"unregex:'%s' filenames:'%v' filesonly:'%t' dirsonly:'%t' ignorehidden:'%t' "+
-		"minsize:'%d' maxsize:'%d' mode:'%s'",
+		"minsize:'%d' maxsize:'%d' mode:'%s' tags:'%v' notematch:'%s'",
 		f.on, f.before, f.after,
 		f.glob, match, f.unglob, unmatch,
 		f.filenames, f.filesonly, f.dirsonly,
 		f.ignorehidden, f.minsize, f.maxsize, f.mode,
+		f.tags, notematch,
 	)
 }
 
@@ -197,6 +239,13 @@ func (f *Filter) hasRegex() bool {
 	return f.matcher.String() != ""
 }
 
+func (f *Filter) hasNoteRegex() bool {
+	if f.notematcher == nil {
+		return false
+	}
+	return f.notematcher.String() != ""
+}
+
 func (f *Filter) hasUnregex() bool {
 	if f.unmatcher == nil {
 		return false
@@ -655,3 +655,41 @@ func TestFilterNotBlank(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterNote(t *testing.T) {
+	type notetest struct {
+		note string
+		tags []string
+		good bool
+	}
+
+	fltr := &filter.Filter{}
+	fltr.AddTags("work", " keep ", "")
+	if err := fltr.SetNoteMatch("v[0-9]"); err != nil {
+		t.Fatal(err)
+	}
+	if fltr.Blank() {
+		t.Fatalf("filter is blank? %s", fltr)
+	}
+
+	for _, test := range []notetest{
+		{"replaced by v2", []string{"keep", "work"}, true},
+		{"replaced by v2", []string{"old", "keep", "work", "x"}, true},
+		{"replaced by v2", []string{"work"}, false},
+		{"replaced by v2", nil, false},
+		{"ask before purging", []string{"keep", "work"}, false},
+		{"", []string{"keep", "work"}, false},
+	} {
+		t.Run(fmt.Sprintf("%s %v", test.note, test.tags), func(t *testing.T) {
+			if fltr.MatchNote(test.note, test.tags) != test.good {
+				t.Fatalf("note '%s' with tags %v should match %t with %s", test.note, test.tags, test.good, fltr)
+			}
+		})
+	}
+
+	t.Run("blank", func(t *testing.T) {
+		if !(&filter.Filter{}).MatchNote("anything", []string{"at", "all"}) {
+			t.Fatal("blank filter didn't match")
+		}
+	})
+}
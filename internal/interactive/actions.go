@@ -0,0 +1,211 @@
+package interactive
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"git.burning.moe/celediel/gt/internal/dirs"
+	"git.burning.moe/celediel/gt/internal/files"
+	"git.burning.moe/celediel/gt/internal/interactive/modes"
+	"git.burning.moe/celediel/gt/internal/prompt"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Actions are how the interactive table restores and cleans files without quitting,
+// and finds what's in the trash again afterwards.
+type Actions struct {
+	Find        func() files.Files
+	Restore     files.RestoreOptions
+	RestoreHere files.RestoreOptions
+	// Confirm asks before restoring too, not just before cleaning
+	Confirm bool
+}
+
+// actionMsg is what happened when an action finished.
+type actionMsg struct {
+	summary string
+	err     error
+}
+
+// Manage shows fls in a table that files can be restored or cleaned from, then keeps
+// showing what's left in the trash afterwards, until it's quit.
+func Manage(fls files.Files, workdir string, actions Actions) error {
+	mdl := newModel(fls, false, false, false, workdir, modes.Interactive)
+	mdl.actions = &actions
+	final, err := tea.NewProgram(mdl, programOptions()...).Run()
+	if err != nil {
+		return err
+	}
+
+	// what happened with an action quit during isn't in the table any more
+	if m, ok := final.(model); ok && m.quitAfter {
+		if m.actionErr != nil {
+			return m.actionErr
+		}
+		fmt.Fprintln(os.Stdout, m.status)
+	}
+	return nil
+}
+
+// act asks to do mode to the selected files, or does it if it doesn't need asking.
+func (m *model) act(mode modes.Mode) tea.Cmd {
+	if len(m.selectedFiles()) == 0 {
+		return nil
+	}
+
+	if mode != modes.Cleaning {
+		if err := m.checkConflicts(mode); err != nil {
+			m.setStatus(err.Error())
+			return nil
+		}
+	}
+
+	if mode == modes.Cleaning || m.actions.Confirm {
+		// answered already with --yes or --assume-no, like on the command line
+		if yes, ok := prompt.Assumed(); ok {
+			if !yes {
+				m.setStatus("not doing anything")
+				return nil
+			}
+			return m.run(mode)
+		}
+		m.pending = mode
+		return nil
+	}
+	return m.run(mode)
+}
+
+// run does mode to the selected files in the background, showing a spinner until it's done.
+func (m *model) run(mode modes.Mode) tea.Cmd {
+	var (
+		fls  = m.selectedFiles()
+		opts = m.restoreOptions(mode)
+	)
+
+	m.pending, m.running, m.runCount = 0, mode, len(fls)
+	m.spinner = spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(darktext))
+
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		if mode == modes.Cleaning {
+			removed, err := files.Remove(fls)
+			return actionMsg{summary: fmt.Sprintf("removed %d files", removed), err: err}
+		}
+		summary, err := files.Restore(fls, opts)
+		return actionMsg{summary: summary, err: err}
+	})
+}
+
+// checkConflicts refuses to restore the selected files for mode if any of them already
+// exist and the policy for that is to ask, since there's no asking from inside the table.
+func (m *model) checkConflicts(mode modes.Mode) error {
+	var (
+		opts     = m.actions.Restore
+		existing []string
+	)
+	if mode == modes.RestoringHere {
+		opts = m.actions.RestoreHere
+	}
+	if opts.OnConflict != files.Ask && (opts.OnConflict != files.Merge || opts.MergeConflict != files.Ask) {
+		return nil
+	}
+	if existing = files.Existing(m.selectedFiles(), opts); len(existing) == 0 {
+		return nil
+	}
+
+	policy := "on-conflict"
+	if opts.OnConflict == files.Merge {
+		policy = "merge-conflict"
+	}
+	return fmt.Errorf("%d of the selected files already exist, and %s is %s, which can't be asked in the table", len(existing), policy, files.Ask)
+}
+
+// restoreOptions are the options to restore with for mode. Anything that turned up where
+// files are restored to after checkConflicts can't be asked about, so it's skipped.
+func (m *model) restoreOptions(mode modes.Mode) files.RestoreOptions {
+	opts := m.actions.Restore
+	if mode == modes.RestoringHere {
+		opts = m.actions.RestoreHere
+	}
+	if opts.OnConflict == files.Ask {
+		opts.OnConflict = files.Skip
+	}
+	if opts.MergeConflict == files.Ask {
+		opts.MergeConflict = files.Skip
+	}
+	return opts
+}
+
+// finish shows what happened with the last action, and reloads the files from the trash.
+func (m *model) finish(msg actionMsg) {
+	m.running, m.actionErr = 0, msg.err
+	m.status = strings.ReplaceAll(msg.summary, "\n", ", ")
+	if msg.err != nil {
+		m.status = msg.err.Error()
+	}
+	m.reload()
+}
+
+// reload finds the files in the trash again, and starts over showing them.
+func (m *model) reload() {
+	fls := m.actions.Find()
+
+	m.files = fls
+	m.totalsize = fls.TotalSize()
+	m.versions = versionNumbers(fls)
+	m.selected = map[string]bool{}
+	m.selectsize = 0
+	m.levels = nil
+	m.browsed = map[string]files.File{}
+	m.browseOrder = nil
+	m.previewKey, m.detailsKey = "", ""
+	m.infos = map[string]fs.FileInfo{}
+
+	m.sort()
+}
+
+// dialog asks to go ahead with the pending action, in place of the table.
+func (m model) dialog() string {
+	var (
+		count    = len(m.selectedFiles())
+		question string
+	)
+	switch {
+	case m.pending == modes.Cleaning && m.reasking:
+		question = fmt.Sprintf("really remove all these %d selected files permanently from the trash forever??", count)
+	case m.pending == modes.Cleaning:
+		question = fmt.Sprintf("remove %d selected files permanently from the trash?", count)
+	case m.pending == modes.RestoringHere:
+		question = fmt.Sprintf("restore %d selected files to %s?", count, dirs.UnExpand(m.actions.RestoreHere.To, ""))
+	default:
+		question = fmt.Sprintf("restore %d selected files?", count)
+	}
+
+	box := style.Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Center,
+		question,
+		strings.Join([]string{styleKey(m.keys.yes), styleKey(m.keys.no)}, darkesttext.Render(" • ")),
+	))
+
+	return style.Render(lipgloss.Place(
+		m.termwidth-border, lipgloss.Height(m.tableView()),
+		lipgloss.Center, lipgloss.Center,
+		box,
+	))
+}
+
+// progress says what's being done while an action runs.
+func (m model) progress() string {
+	verb := "restoring"
+	if m.running == modes.Cleaning {
+		verb = "removing"
+	}
+	text := fmt.Sprintf("%s %s %d files…", m.spinner.View(), verb, m.runCount)
+	if m.quitAfter {
+		text += " then quitting"
+	}
+	return regulartext.Render(text)
+}
@@ -0,0 +1,65 @@
+package interactive
+
+import (
+	"testing"
+
+	"git.burning.moe/celediel/gt/internal/files"
+	"git.burning.moe/celediel/gt/internal/interactive/modes"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// managing is a table files can be restored and cleaned from, that never finds anything new.
+func managing(fls files.Files, confirm bool) model {
+	m := newModel(fls, false, false, false, "", modes.Interactive)
+	m.actions = &Actions{Find: func() files.Files { return fls }, Confirm: confirm}
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestQuitWhileRunning(t *testing.T) {
+	m := managing(files.Files{fake{path: "/d/a"}}, false)
+	m.running = modes.Restoring
+
+	m = press(m, runes("q"))
+	if m.quitting || !m.quitAfter {
+		t.Fatal("expected quitting to wait for the restore to finish")
+	}
+
+	updated, cmd := m.Update(actionMsg{summary: "restored 1 files"})
+	if m = updated.(model); !m.quitting || cmd == nil {
+		t.Fatal("expected to quit once the restore finished")
+	}
+	if m.status != "restored 1 files" {
+		t.Fatalf("expected what happened to be kept to print, got '%s'", m.status)
+	}
+}
+
+func TestCleanAsksTwice(t *testing.T) {
+	for _, confirm := range []bool{false, true} {
+		m := press(managing(files.Files{fake{path: "/d/a"}}, confirm), spaceKey, runes("c"))
+		if m.pending != modes.Cleaning {
+			t.Fatal("expected to be asked before cleaning")
+		}
+
+		m = press(m, runes("y"))
+		if confirm && (m.running != 0 || !m.reasking) {
+			t.Fatal("expected to be asked again when confirming, like on the command line")
+		}
+		if confirm {
+			m = press(m, runes("y"))
+		}
+		if m.running != modes.Cleaning || m.pending != 0 {
+			t.Fatalf("expected cleaning to start once it's been answered, confirm %t", confirm)
+		}
+	}
+
+	// saying no the second time doesn't clean anything
+	m := press(managing(files.Files{fake{path: "/d/a"}}, true), spaceKey, runes("c"), runes("y"), runes("n"))
+	if m.running != 0 || m.pending != 0 || m.reasking {
+		t.Fatal("expected nothing to be cleaned")
+	}
+}
@@ -0,0 +1,115 @@
+package interactive
+
+import (
+	"os"
+	"strings"
+
+	"git.burning.moe/celediel/gt/internal/files"
+)
+
+// level is a trashed directory the table has gone into, and what it was showing before.
+type level struct {
+	dir    files.File
+	files  files.Files
+	filter string
+}
+
+// canBrowse checks if file is a directory in the trash, that can be gone into.
+func canBrowse(file files.File) bool {
+	if v, ok := file.(version); ok {
+		file = v.File
+	}
+	switch file.(type) {
+	case files.TrashInfo, files.TrashEntry:
+		return file.IsDir()
+	default:
+		return false
+	}
+}
+
+// descend shows what's inside the trashed directory at index.
+func (m *model) descend(index int) {
+	file := m.shown[index]
+	if v, ok := file.(version); ok {
+		file = v.File
+	}
+
+	entries, err := files.Entries(file)
+	if err != nil {
+		return
+	}
+
+	m.levels = append(m.levels, level{dir: file, files: m.files, filter: m.filter})
+	for _, entry := range entries {
+		if _, ok := m.browsed[entry.String()]; !ok {
+			m.browsed[entry.String()] = entry
+			m.browseOrder = append(m.browseOrder, entry.String())
+		}
+	}
+
+	m.files = entries
+	m.filter = ""
+	m.table.SetCursor(0)
+	m.sort()
+}
+
+// ascend goes back out of the directory being shown.
+func (m *model) ascend() {
+	last := m.levels[len(m.levels)-1]
+	m.levels = m.levels[:len(m.levels)-1]
+	m.files, m.filter = last.files, last.filter
+	m.sort()
+
+	// put the cursor back on the directory
+	for i, file := range m.shown {
+		if v, ok := file.(version); ok {
+			file = v.File
+		}
+		if file.String() == last.dir.String() {
+			m.table.SetCursor(i)
+			break
+		}
+	}
+}
+
+// selectedInside checks if anything has been selected inside the trashed directory file.
+func (m *model) selectedInside(file files.File) bool {
+	if v, ok := file.(version); ok {
+		file = v.File
+	}
+
+	var dir string
+	switch file := file.(type) {
+	case files.TrashInfo:
+		dir = file.TrashPath()
+	case files.TrashEntry:
+		dir = file.TrashPath()
+	default:
+		return false
+	}
+
+	for _, name := range m.browseOrder {
+		entry, ok := m.browsed[name].(files.TrashEntry)
+		if ok && m.selected[name] && strings.HasPrefix(entry.TrashPath(), dir+string(os.PathSeparator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// topFiles returns the files shown before going into any directories.
+func (m *model) topFiles() files.Files {
+	if len(m.levels) > 0 {
+		return m.levels[0].files
+	}
+	return m.files
+}
+
+// breadcrumb is the path through the directories gone into.
+func (m *model) breadcrumb() string {
+	names := make([]string, 0, len(m.levels))
+	for _, l := range m.levels {
+		names = append(names, l.dir.Name())
+	}
+	return strings.Join(names, "/")
+}
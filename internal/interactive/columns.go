@@ -0,0 +1,304 @@
+package interactive
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"git.burning.moe/celediel/gt/internal/dirs"
+	"git.burning.moe/celediel/gt/internal/files"
+	"git.burning.moe/celediel/gt/internal/interactive/modes"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	checkColumn string = "check"
+	// minColumnW is as narrow as columns that share the width get, before giving up
+	minColumnW int = 6
+)
+
+// column is something about files that can be shown in the table.
+type column struct {
+	name, title string
+	// fixed columns are as wide as what's in them, and the others share what's left
+	fixed bool
+	// trashed columns are only shown for files in the trash
+	trashed bool
+	// folders columns have something to show for the folders in the tree
+	folders bool
+	// sorts are the sorting fields that sort by the column
+	sorts []string
+	value func(m *model, file files.File) string
+}
+
+// allColumns are all the columns there are, in the order they're listed.
+var allColumns = []column{
+	{name: "name", title: "filename", folders: true, sorts: []string{"name", "extension"}, value: (*model).nameFor},
+	{name: "path", title: "path", sorts: []string{"path"}, value: func(m *model, file files.File) string {
+		return dirs.UnExpand(filepath.Dir(file.Path()), m.workdir)
+	}},
+	{name: "full-path", title: "full path", sorts: []string{"path"}, value: func(m *model, file files.File) string {
+		return dirs.UnExpand(file.Path(), m.workdir)
+	}},
+	{name: "trash", title: "trash", trashed: true, value: func(_ *model, file files.File) string {
+		return dirs.UnExpand(files.TrashDir(newest(file)), "")
+	}},
+	{name: "trashed", title: "trashed", fixed: true, folders: true, sorts: []string{"date"}, value: func(_ *model, file files.File) string {
+		return humanize.Time(file.Date())
+	}},
+	{name: "modified", title: "modified", fixed: true, trashed: true, value: func(m *model, file files.File) string {
+		if info := m.info(file); info != nil {
+			return humanize.Time(info.ModTime())
+		}
+		return ""
+	}},
+	{name: "size", title: "size", fixed: true, folders: true, sorts: []string{"size"}, value: func(_ *model, file files.File) string {
+		return humanize.Bytes(uint64(file.Filesize()))
+	}},
+	{name: "mode", title: "mode", fixed: true, value: func(_ *model, file files.File) string {
+		return newest(file).Mode().String()
+	}},
+	{name: "owner", title: "owner", fixed: true, value: func(m *model, file files.File) string {
+		if info := m.info(file); info != nil {
+			return m.owner(info)
+		}
+		return ""
+	}},
+	{name: "type", title: "type", fixed: true, sorts: []string{"directories"}, value: func(_ *model, file files.File) string {
+		return fileType(newest(file).Mode())
+	}},
+	{name: "id", title: "id", fixed: true, trashed: true, value: func(_ *model, file files.File) string {
+		return shortID(file)
+	}},
+	{name: "note", title: "note", value: func(_ *model, file files.File) string {
+		return noteFor(file)
+	}},
+}
+
+// shownColumns are the names of the columns the table starts with.
+var shownColumns = []string{"name", "path", "trashed", "size", "id", "note"}
+
+// SetColumns sets the columns the table starts with, from their space separated names.
+func SetColumns(names string) error {
+	fields := strings.Fields(names)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var unique []string
+	for _, name := range fields {
+		if !slices.ContainsFunc(allColumns, func(c column) bool { return c.name == name }) {
+			return fmt.Errorf("unknown column '%s' (possible values: %s)", name, strings.Join(columnNames(), ", "))
+		}
+		if !slices.Contains(unique, name) {
+			unique = append(unique, name)
+		}
+	}
+
+	shownColumns = unique
+	return nil
+}
+
+func columnNames() []string {
+	names := make([]string, 0, len(allColumns))
+	for _, c := range allColumns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// visibleColumns are the columns shown, leaving out ones that don't apply to the files.
+func (m *model) visibleColumns() (visible []column) {
+	for _, name := range m.shownColumns {
+		i := slices.IndexFunc(allColumns, func(c column) bool { return c.name == name })
+		c := allColumns[i]
+
+		switch {
+		case c.trashed && m.mode == modes.Trashing:
+			continue
+		case c.name == "note" && !m.showNotes():
+			continue
+		case c.name == "trashed" && m.mode == modes.Trashing:
+			// files that aren't trashed yet only have the date they were modified
+			c.title = "modified"
+		}
+		visible = append(visible, c)
+	}
+
+	if len(visible) == 0 {
+		// there has to be something to show
+		visible = append(visible, allColumns[0])
+	}
+	return
+}
+
+// toggleColumn shows the column called name if it's hidden, or hides it if it's showing.
+func (m *model) toggleColumn(name string) error {
+	if !slices.Contains(columnNames(), name) {
+		return fmt.Errorf("unknown column '%s' (possible values: %s)", name, strings.Join(columnNames(), ", "))
+	}
+
+	if i := slices.Index(m.shownColumns, name); i >= 0 {
+		m.shownColumns = slices.Delete(slices.Clone(m.shownColumns), i, i+1)
+	} else {
+		m.shownColumns = append(slices.Clone(m.shownColumns), name)
+	}
+	m.applyFilter()
+	return nil
+}
+
+// fitColumns sizes columns to the widest of what's in them in rows, and shares out
+// what's left of the table's width, or takes what's too much from the ones that aren't
+// fixed first.
+func (m *model) fitColumns(visible []column, rows []table.Row) []table.Column {
+	var (
+		columns = make([]table.Column, 0, len(visible)+1)
+		fixed   = make([]bool, 0, len(visible)+1)
+	)
+
+	for i, c := range visible {
+		title := c.title
+		if slices.Contains(c.sorts, m.sorting.Field()) {
+			title += " " + m.sorting.Arrow()
+		}
+
+		width := lipgloss.Width(title)
+		for _, row := range rows {
+			if i < len(row) {
+				width = max(width, lipgloss.Width(row[i]))
+			}
+		}
+		columns = append(columns, table.Column{Title: title, Width: width})
+		fixed = append(fixed, c.fixed)
+	}
+
+	if !m.readonly {
+		columns = append(columns, table.Column{Title: uncheck, Width: lipgloss.Width(uncheck)})
+		fixed = append(fixed, true)
+	}
+
+	// every column is padded by a space on each side
+	available := m.tableWidth() - 2*len(columns)
+	used := 0
+	for _, c := range columns {
+		used += c.Width
+	}
+
+	// shrink the widest column that isn't fixed until they fit, then any of them if
+	// they still don't, until they can't shrink any more
+	for _, shrinkFixed := range []bool{false, true} {
+		for used > available {
+			widest := -1
+			for i, c := range columns {
+				if (shrinkFixed || !fixed[i] && c.Width > minColumnW) && c.Width > 1 &&
+					(widest < 0 || c.Width > columns[widest].Width) {
+					widest = i
+				}
+			}
+			if widest < 0 {
+				break
+			}
+			columns[widest].Width--
+			used--
+		}
+	}
+
+	// and give what's left over to the first one that isn't fixed, or the first one
+	if used < available {
+		grow := max(slices.Index(fixed, false), 0)
+		columns[grow].Width += available - used
+	}
+
+	return columns
+}
+
+// nameFor is the name of file as it's shown, marking directories, groups, and the versions
+// in them, and in the tree, folders, and the files in them.
+func (m *model) nameFor(file files.File) string {
+	name := dirs.PercentDecode(file.Name())
+	if file.IsDir() {
+		name += string(os.PathSeparator)
+	}
+
+	var prefix string
+	if m.inTree() {
+		prefix = indent
+	}
+
+	switch file := file.(type) {
+	case folder:
+		name = dirs.UnExpand(file.path, m.workdir)
+		if !strings.HasSuffix(name, string(os.PathSeparator)) {
+			name += string(os.PathSeparator)
+		}
+		return fmt.Sprintf("%s%s (%s)", arrowFor(m.expanded[expandKey(file)]), name, pluralFiles(file.count()))
+	case group:
+		return fmt.Sprintf("%s%s%s (%d versions)", prefix, arrowFor(m.expanded[expandKey(file)]), name, len(file.children))
+	case version:
+		return fmt.Sprintf("%s%s#%d %s", prefix, indent, file.number, name)
+	}
+	return prefix + name
+}
+
+func arrowFor(isExpanded bool) string {
+	if isExpanded {
+		return expanded
+	}
+	return collapsed
+}
+
+// info reads what's on disk about the file a row is showing, once.
+func (m *model) info(file files.File) fs.FileInfo {
+	key := newest(file).String()
+	if info, ok := m.infos[key]; ok {
+		return info
+	}
+
+	info, err := os.Lstat(files.Location(newest(file)))
+	if err != nil {
+		info = nil
+	}
+	m.infos[key] = info
+	return info
+}
+
+// owner is who owns the file info is about, looked up once for each owner and group.
+func (m *model) owner(info fs.FileInfo) string {
+	uid, gid, ok := files.OwnerIDs(info)
+	if !ok {
+		return ""
+	}
+
+	key := [2]uint32{uid, gid}
+	if owner, ok := m.owners[key]; ok {
+		return owner
+	}
+
+	owner, group := files.Owner(info)
+	m.owners[key] = owner + ":" + group
+	return m.owners[key]
+}
+
+// fileType is what kind of file mode is for, in a word.
+func fileType(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "link"
+	case mode&fs.ModeNamedPipe != 0:
+		return "pipe"
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeDevice != 0:
+		return "device"
+	default:
+		return "file"
+	}
+}
@@ -0,0 +1,85 @@
+package interactive
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+func TestFitColumns(t *testing.T) {
+	var (
+		visible = []column{
+			{name: "name", title: "name"},
+			{name: "path", title: "path"},
+			{name: "size", title: "size", fixed: true},
+		}
+		rows = []table.Row{{strings.Repeat("n", 30), strings.Repeat("p", 20), "10 B"}}
+		// the widest of each, and the check column
+		natural = []int{30, 20, 4, 1}
+		// every column is padded by a space on each side
+		padding = 2 * len(natural)
+	)
+
+	for width := 80; width >= 12; width-- {
+		m := model{termwidth: width}
+		columns := m.fitColumns(visible, rows)
+
+		used := padding
+		for _, c := range columns {
+			used += c.Width
+		}
+		if used != width {
+			t.Fatalf("at %d wide: expected the columns to fill the table, got %d", width, used)
+		}
+
+		if width >= 30+20+4+1+padding {
+			// what's left over goes to the first column that isn't fixed
+			if columns[0].Width != width-padding-25 || columns[1].Width != 20 {
+				t.Fatalf("at %d wide: expected only name to grow, got %v", width, columns)
+			}
+			continue
+		}
+
+		if width >= 2*minColumnW+4+1+padding {
+			// fixed columns are shrunk last, and the others no smaller than they have to be
+			if columns[2].Width != natural[2] || columns[3].Width != natural[3] {
+				t.Fatalf("at %d wide: expected size and check left alone, got %v", width, columns)
+			}
+			if columns[0].Width < minColumnW || columns[1].Width < minColumnW {
+				t.Fatalf("at %d wide: expected name and path at least %d wide, got %v", width, minColumnW, columns)
+			}
+		}
+	}
+
+	m := model{termwidth: 40, readonly: true}
+	if columns := m.fitColumns(visible, rows); len(columns) != len(visible) {
+		t.Fatalf("expected no check column when read only, got %d columns", len(columns))
+	}
+}
+
+func TestSetColumns(t *testing.T) {
+	defaults := slices.Clone(shownColumns)
+	t.Cleanup(func() { shownColumns = defaults })
+
+	if err := SetColumns(""); err != nil || !slices.Equal(shownColumns, defaults) {
+		t.Fatalf("expected nothing to leave the defaults, got %v, %v", shownColumns, err)
+	}
+
+	// columns named again are shown where they're first named
+	if err := SetColumns("name path name size path"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"name", "path", "size"}
+	if !slices.Equal(shownColumns, want) {
+		t.Fatalf("expected %v, got %v", want, shownColumns)
+	}
+
+	if err := SetColumns("name bogus"); err == nil || !strings.Contains(err.Error(), "unknown column 'bogus'") {
+		t.Fatalf("expected an unknown column to be an error, got %v", err)
+	}
+	if !slices.Equal(shownColumns, want) {
+		t.Fatalf("expected a bad list to leave the columns alone, got %v", shownColumns)
+	}
+}
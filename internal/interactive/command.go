@@ -0,0 +1,424 @@
+package interactive
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"git.burning.moe/celediel/gt/internal/files"
+	"git.burning.moe/celediel/gt/internal/interactive/modes"
+	"git.burning.moe/celediel/gt/internal/interactive/sorting"
+
+	"github.com/adrg/xdg"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	maxHistory        int = 500
+	executePerm           = fs.FileMode(0755)
+	noExecuteUserPerm     = fs.FileMode(0600)
+)
+
+// HistoryPath is where commands run from the command line are remembered.
+var HistoryPath = filepath.Join(xdg.StateHome, "gt", "history")
+
+// command is something that can be run from the command line, with what its first
+// argument can be, to complete it.
+type command struct {
+	name string
+	args []string
+	run  func(m *model, args []string) (tea.Cmd, error)
+}
+
+var commands = []command{
+	{name: "sort", args: sorting.Fields, run: (*model).sortCommand},
+	{name: "filter", args: conditionFields, run: (*model).filterCommand},
+	{name: "select", args: []string{"all", "none", "invert", "dir", "glob", "regex"}, run: (*model).selectCommand},
+	{name: "unselect", args: []string{"glob", "regex"}, run: (*model).unselectCommand},
+	{name: "goto", run: (*model).gotoCommand},
+	{name: "column", args: columnNames(), run: (*model).columnCommand},
+	{name: "restore", args: []string{"here"}, run: (*model).restoreCommand},
+	{name: "clean", run: (*model).cleanCommand},
+	{name: "preview", run: func(m *model, _ []string) (tea.Cmd, error) { return m.togglePreview(), nil }},
+	{name: "details", run: func(m *model, _ []string) (tea.Cmd, error) { return m.toggleDetails(), nil }},
+	{name: "tree", run: func(m *model, _ []string) (tea.Cmd, error) {
+		m.toggleTree()
+		return nil, nil
+	}},
+	{name: "quit", run: func(m *model, _ []string) (tea.Cmd, error) {
+		*m, _ = m.quit(true)
+		return tea.Quit, nil
+	}},
+}
+
+// openCommandLine starts taking a command.
+func (m *model) openCommandLine() {
+	m.cmdline = textinput.New()
+	m.cmdline.Prompt = ":"
+	m.cmdline.Cursor.SetMode(cursor.CursorStatic)
+	m.cmdline.Focus()
+	m.history = readHistory()
+	m.historyIndex = len(m.history)
+	m.completions = nil
+	m.commanding = true
+}
+
+// updateCommandLine handles keys pressed while the command line is open.
+func (m *model) updateCommandLine(msg tea.KeyMsg) tea.Cmd {
+	m.completions = nil
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.commanding = false
+		return nil
+	case tea.KeyEnter:
+		m.commanding = false
+		line := strings.TrimSpace(m.cmdline.Value())
+		if line == "" {
+			return nil
+		}
+		writeHistory(append(m.history, line))
+		cmd, err := m.runCommand(line)
+		if err != nil {
+			m.setStatus(err.Error())
+		}
+		return cmd
+	case tea.KeyTab:
+		m.complete()
+		return nil
+	case tea.KeyUp:
+		if m.historyIndex > 0 {
+			m.historyIndex--
+			m.cmdline.SetValue(m.history[m.historyIndex])
+			m.cmdline.CursorEnd()
+		}
+		return nil
+	case tea.KeyDown:
+		if m.historyIndex < len(m.history) {
+			m.historyIndex++
+			if m.historyIndex == len(m.history) {
+				m.cmdline.SetValue("")
+			} else {
+				m.cmdline.SetValue(m.history[m.historyIndex])
+			}
+			m.cmdline.CursorEnd()
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.cmdline, cmd = m.cmdline.Update(msg)
+	return cmd
+}
+
+// runCommand runs line, a command name, or any unique prefix of one, and its arguments.
+func (m *model) runCommand(line string) (tea.Cmd, error) {
+	fields := strings.Fields(line)
+
+	var found []command
+	for _, c := range commands {
+		if c.name == fields[0] {
+			found = []command{c}
+			break
+		}
+		if strings.HasPrefix(c.name, fields[0]) {
+			found = append(found, c)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("unknown command '%s'", fields[0])
+	case 1:
+		return found[0].run(m, fields[1:])
+	default:
+		names := make([]string, 0, len(found))
+		for _, c := range found {
+			names = append(names, c.name)
+		}
+		return nil, fmt.Errorf("'%s' could be %s", fields[0], strings.Join(names, ", "))
+	}
+}
+
+// complete fills in the command name, or its first argument, being typed, as far as it's
+// the same for everything it could be, and shows what it could be if that's more than one.
+func (m *model) complete() {
+	var (
+		line       = m.cmdline.Value()
+		fields     = strings.Fields(line)
+		candidates []string
+		word       string
+	)
+
+	switch {
+	case len(fields) == 0 || len(fields) == 1 && !strings.HasSuffix(line, " "):
+		if len(fields) == 1 {
+			word = fields[0]
+		}
+		for _, c := range commands {
+			candidates = append(candidates, c.name)
+		}
+	case len(fields) == 1 || len(fields) == 2 && !strings.HasSuffix(line, " "):
+		if len(fields) == 2 {
+			word = fields[1]
+		}
+		for _, c := range commands {
+			if c.name == fields[0] {
+				candidates = c.args
+			}
+		}
+	default:
+		return
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return
+	}
+
+	completed := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, completed) {
+			completed = completed[:len(completed)-1]
+		}
+	}
+	if len(matches) == 1 {
+		completed += " "
+	} else {
+		m.completions = matches
+	}
+
+	m.cmdline.SetValue(strings.TrimSuffix(line, word) + completed)
+	m.cmdline.CursorEnd()
+}
+
+func (m model) commandLine() string {
+	line := regulartext.Render(m.cmdline.View())
+	if len(m.completions) > 0 {
+		line += darkertext.Render(strings.Join(m.completions, "  "))
+	}
+	return line
+}
+
+func (m *model) sortCommand(args []string) (tea.Cmd, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, fmt.Errorf("usage: sort %s [asc|desc]", strings.Join(sorting.Fields, "|"))
+	}
+
+	var direction string
+	if len(args) == 2 {
+		direction = args[1]
+	}
+	s, err := sorting.Parse(args[0], direction)
+	if err != nil {
+		return nil, err
+	}
+
+	m.sorting = s
+	m.sort()
+	return nil, nil
+}
+
+// filterCommand shows only files matching all of args, conditions like size>1M, or clears
+// them if there are none.
+func (m *model) filterCommand(args []string) (tea.Cmd, error) {
+	conditions := make([]condition, 0, len(args))
+	for _, arg := range args {
+		c, err := parseCondition(arg)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+	}
+
+	m.conditions = conditions
+	m.table.SetCursor(0)
+	m.applyFilter()
+	return nil, nil
+}
+
+func (m *model) selectCommand(args []string) (tea.Cmd, error) {
+	if len(args) == 1 {
+		switch args[0] {
+		case "all":
+			m.selectAll()
+			return nil, nil
+		case "none":
+			m.unselectAll()
+			return nil, nil
+		case "invert":
+			m.invertSelection()
+			return nil, nil
+		case "dir":
+			m.selectSameDir()
+			return nil, nil
+		}
+	}
+	return nil, m.selectMatching(args, true)
+}
+
+func (m *model) unselectCommand(args []string) (tea.Cmd, error) {
+	return nil, m.selectMatching(args, false)
+}
+
+// selectMatching selects, or unselects, the shown files with names matching a glob or
+// regex, from args like "glob *.iso".
+func (m *model) selectMatching(args []string, selected bool) error {
+	if m.readonly {
+		return nil
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("expected glob or regex, and a pattern")
+	}
+
+	var match func(string) bool
+	switch args[0] {
+	case "glob":
+		if _, err := filepath.Match(args[1], ""); err != nil {
+			return fmt.Errorf("bad glob '%s': %w", args[1], err)
+		}
+		match = func(name string) bool {
+			matched, _ := filepath.Match(args[1], name)
+			return matched
+		}
+	case "regex":
+		regex, err := regexp.Compile(args[1])
+		if err != nil {
+			return err
+		}
+		match = regex.MatchString
+	default:
+		return fmt.Errorf("can't select by '%s' (possible values: glob, regex)", args[0])
+	}
+
+	var count int
+	for _, file := range m.fltrfiles {
+		if match(file.Name()) {
+			m.setSelected(file, selected)
+			count++
+		}
+	}
+	m.updateRows()
+
+	verb := "selected"
+	if !selected {
+		verb = "unselected"
+	}
+	m.setStatus(fmt.Sprintf("%s %d files", verb, count))
+	return nil
+}
+
+// gotoCommand moves the cursor to the first file named args, or starting with or containing it.
+func (m *model) gotoCommand(args []string) (tea.Cmd, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("usage: goto NAME")
+	}
+	name := strings.ToLower(strings.Join(args, " "))
+
+	for _, test := range []func(string) bool{
+		func(s string) bool { return s == name },
+		func(s string) bool { return strings.HasPrefix(s, name) },
+		func(s string) bool { return strings.Contains(s, name) },
+	} {
+		if i := slices.IndexFunc(m.shown, func(f files.File) bool { return test(strings.ToLower(f.Name())) }); i >= 0 {
+			m.table.SetCursor(i)
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("no files named '%s'", name)
+}
+
+// columnCommand shows or hides the columns named args.
+func (m *model) columnCommand(args []string) (tea.Cmd, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("usage: column %s", strings.Join(columnNames(), "|"))
+	}
+	for _, name := range args {
+		if err := m.toggleColumn(name); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (m *model) restoreCommand(args []string) (tea.Cmd, error) {
+	mode := modes.Restoring
+	if len(args) > 0 && args[0] == "here" {
+		mode = modes.RestoringHere
+	}
+	return m.doCommand(mode)
+}
+
+func (m *model) cleanCommand(_ []string) (tea.Cmd, error) {
+	return m.doCommand(modes.Cleaning)
+}
+
+// doCommand does mode to the selected files, like pressing its key.
+func (m *model) doCommand(mode modes.Mode) (tea.Cmd, error) {
+	if m.readonly || len(m.selected) == 0 {
+		return nil, fmt.Errorf("no files selected")
+	}
+
+	var cmd tea.Cmd
+	switch {
+	case m.mode == modes.Interactive:
+		*m, cmd = m.execute(mode)
+	case m.mode == mode, m.mode == modes.Restoring && mode == modes.RestoringHere:
+		m.mode = mode
+		*m, cmd = m.quit(false)
+	default:
+		return nil, fmt.Errorf("can't do that while %s", strings.ToLower(m.mode.String()))
+	}
+	return cmd, nil
+}
+
+// setStatus shows text in the status line, making room for it if it wasn't showing.
+func (m *model) setStatus(text string) {
+	m.status = text
+	m.updateTableHeight()
+}
+
+func readHistory() (history []string) {
+	f, err := os.Open(HistoryPath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			history = append(history, line)
+		}
+	}
+	return
+}
+
+// writeHistory saves history, without repeating the last command, and only the most recent.
+func writeHistory(history []string) {
+	if n := len(history); n > 1 && history[n-1] == history[n-2] {
+		history = history[:n-1]
+	}
+	history = history[max(len(history)-maxHistory, 0):]
+
+	if err := os.MkdirAll(filepath.Dir(HistoryPath), executePerm); err != nil {
+		log.Error(err)
+		return
+	}
+	if err := os.WriteFile(HistoryPath, []byte(strings.Join(history, "\n")+"\n"), noExecuteUserPerm); err != nil {
+		log.Error(err)
+	}
+}
@@ -0,0 +1,194 @@
+package interactive
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.burning.moe/celediel/gt/internal/files"
+
+	"github.com/dustin/go-humanize"
+	"github.com/ijt/go-anytime"
+)
+
+const day = 24 * time.Hour
+
+// condition is one term of a :filter command, that files have to match to be shown.
+type condition struct {
+	text  string
+	match func(files.File) bool
+}
+
+// conditionFields are what conditions can be about.
+var conditionFields = []string{"name", "path", "trashed", "size", "type"}
+
+// operators are checked longest first, so <= isn't taken for <
+var operators = []string{"<=", ">=", "!=", "<", ">", "=", "~"}
+
+var durationRegex = regexp.MustCompile(`^(\d+)([smhdwy])$`)
+
+// parseCondition parses a term like size>1M, trashed<7d, name~regex, name=*.iso, or type=dir.
+func parseCondition(term string) (condition, error) {
+	var field, op, value string
+find:
+	for i := 1; i < len(term); i++ {
+		for _, o := range operators {
+			if strings.HasPrefix(term[i:], o) {
+				field, op, value = term[:i], o, term[i+len(o):]
+				break find
+			}
+		}
+	}
+	if op == "" {
+		return condition{}, fmt.Errorf("'%s' isn't a condition, like size>1M or trashed<7d", term)
+	}
+
+	var (
+		match func(files.File) bool
+		err   error
+	)
+	switch field {
+	case "name":
+		match, err = matchText(op, value, func(f files.File) string { return f.Name() })
+	case "path":
+		match, err = matchText(op, value, func(f files.File) string { return f.Path() })
+	case "trashed", "date":
+		match, err = matchDate(op, value)
+	case "size":
+		match, err = matchSize(op, value)
+	case "type":
+		match, err = matchType(op, value)
+	default:
+		err = fmt.Errorf("can't filter on '%s' (possible values: %s)", field, strings.Join(conditionFields, ", "))
+	}
+	if err != nil {
+		return condition{}, err
+	}
+
+	return condition{text: term, match: match}, nil
+}
+
+// matchConditions checks if file matches all the conditions set with the filter command.
+func (m *model) matchConditions(file files.File) bool {
+	for _, c := range m.conditions {
+		if !c.match(file) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchText matches text globs with = and !=, and regexes with ~.
+func matchText(op, value string, text func(files.File) string) (func(files.File) bool, error) {
+	switch op {
+	case "=", "!=":
+		if _, err := filepath.Match(value, ""); err != nil {
+			return nil, fmt.Errorf("bad glob '%s': %w", value, err)
+		}
+		want := op == "="
+		return func(f files.File) bool {
+			matched, _ := filepath.Match(value, text(f))
+			return matched == want
+		}, nil
+	case "~":
+		regex, err := regexp.Compile(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(f files.File) bool { return regex.MatchString(text(f)) }, nil
+	default:
+		return nil, fmt.Errorf("names and paths can only be compared with =, != or ~")
+	}
+}
+
+// matchDate compares how long ago files were trashed with a duration like 7d, or
+// when they were trashed with a date like 2024-01-01 or yesterday.
+func matchDate(op, value string) (func(files.File) bool, error) {
+	now := time.Now()
+
+	if age, ok := parseAge(value); ok {
+		return compare(op, func(f files.File) int64 { return int64(now.Sub(f.Date())) }, int64(age))
+	}
+
+	date, err := anytime.Parse(value, now)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' isn't a duration like 7d or a date", value)
+	}
+	return compare(op, func(f files.File) int64 { return f.Date().Unix() }, date.Unix())
+}
+
+func matchSize(op, value string) (func(files.File) bool, error) {
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		return nil, err
+	}
+	return compare(op, func(f files.File) int64 { return f.Filesize() }, int64(size))
+}
+
+func matchType(op, value string) (func(files.File) bool, error) {
+	var dir bool
+	switch value {
+	case "dir", "d":
+		dir = true
+	case "file", "f":
+	default:
+		return nil, fmt.Errorf("unknown type '%s' (possible values: file, dir)", value)
+	}
+
+	switch op {
+	case "=":
+		return func(f files.File) bool { return f.IsDir() == dir }, nil
+	case "!=":
+		return func(f files.File) bool { return f.IsDir() != dir }, nil
+	default:
+		return nil, fmt.Errorf("types can only be compared with = or !=")
+	}
+}
+
+// compare makes a condition comparing what get returns for a file with want.
+func compare(op string, get func(files.File) int64, want int64) (func(files.File) bool, error) {
+	var test func(got int64) bool
+	switch op {
+	case "<":
+		test = func(got int64) bool { return got < want }
+	case "<=":
+		test = func(got int64) bool { return got <= want }
+	case ">":
+		test = func(got int64) bool { return got > want }
+	case ">=":
+		test = func(got int64) bool { return got >= want }
+	case "=":
+		test = func(got int64) bool { return got == want }
+	case "!=":
+		test = func(got int64) bool { return got != want }
+	default:
+		return nil, fmt.Errorf("%s can't be used for numbers and dates", op)
+	}
+	return func(f files.File) bool { return test(get(f)) }, nil
+}
+
+// parseAge parses a duration like 30s, 10m, 12h, 7d, 2w, or 1y.
+func parseAge(value string) (time.Duration, bool) {
+	parts := durationRegex.FindStringSubmatch(value)
+	if parts == nil {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+
+	unit := map[string]time.Duration{
+		"s": time.Second,
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": day,
+		"w": 7 * day,
+		"y": 365 * day,
+	}[parts[2]]
+	return time.Duration(n) * unit, true
+}
@@ -0,0 +1,92 @@
+package interactive
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"git.burning.moe/celediel/gt/internal/files"
+	"git.burning.moe/celediel/gt/internal/interactive/modes"
+)
+
+// fake is a trashed file that's only what it says it is.
+type fake struct {
+	path string
+	size int64
+	date time.Time
+	dir  bool
+}
+
+func (f fake) Name() string      { return filepath.Base(f.path) }
+func (f fake) Path() string      { return f.path }
+func (f fake) Date() time.Time   { return f.date }
+func (f fake) Filesize() int64   { return f.size }
+func (f fake) IsDir() bool       { return f.dir }
+func (f fake) Mode() fs.FileMode { return 0o644 }
+func (f fake) String() string    { return f.path + f.date.String() }
+
+func paths(fls files.Files) []string {
+	var out []string
+	for _, file := range fls {
+		out = append(out, file.Path())
+	}
+	return out
+}
+
+func TestFilterCommand(t *testing.T) {
+	var (
+		now  = time.Now()
+		iso  = fake{path: "/home/user/linux.iso", size: 4_000_000_000, date: now.Add(-10 * day)}
+		note = fake{path: "/home/user/notes/todo.txt", size: 2_000, date: now.Add(-time.Hour)}
+		dir  = fake{path: "/tmp/build", size: 100, date: now.Add(-2 * day), dir: true}
+		m    = newModel(files.Files{iso, note, dir}, false, false, false, "", modes.Interactive)
+	)
+
+	// each command filters everything again, rather than what the last one left
+	for _, step := range []struct {
+		line string
+		want []string
+	}{
+		{"filter size>1M", []string{iso.path}},
+		{"filter size<=2KB", []string{note.path, dir.path}},
+		{"filter trashed<7d type=f", []string{note.path}},
+		{"filter trashed>=1w", []string{iso.path}},
+		{"filter name!=*.iso path~/home/", []string{note.path}},
+		{"filter name~^b type=dir", []string{dir.path}},
+		{"filter", []string{iso.path, note.path, dir.path}},
+	} {
+		if _, err := m.runCommand(step.line); err != nil {
+			t.Fatalf("%s: %v", step.line, err)
+		}
+		got := paths(m.shown)
+		slices.Sort(got)
+		slices.Sort(step.want)
+		if !slices.Equal(got, step.want) {
+			t.Fatalf("%s: expected %v, got %v", step.line, step.want, got)
+		}
+	}
+
+	// a bad condition leaves the ones already there alone
+	if _, err := m.runCommand("filter type=f"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.runCommand("filter size>lots"); err == nil {
+		t.Fatal("expected an error for a size that isn't one")
+	}
+	if len(m.conditions) != 1 || len(m.shown) != 2 {
+		t.Fatalf("expected the last good filter to stay, got %d conditions showing %v", len(m.conditions), paths(m.shown))
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	for _, term := range []string{
+		"size", "owner=root", "size>lots", "trashed<whenever", "name<b", "name~(", "name=[",
+		"type=link", "type>dir", "size~1M",
+	} {
+		if c, err := parseCondition(term); err == nil {
+			t.Errorf("expected '%s' not to parse, got %+v", term, c)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package interactive
+
+import (
+	"fmt"
+	"strings"
+
+	"git.burning.moe/celediel/gt/internal/files"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+const detailsDateFmt string = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// detailsMsg is the details of the file with key, once they've been read.
+type detailsMsg struct {
+	key     string
+	details files.Details
+}
+
+// toggleDetails shows or hides the details of the hovered file in place of the table.
+func (m *model) toggleDetails() tea.Cmd {
+	m.detailing = !m.detailing
+	m.detailsKey = ""
+	return m.refreshDetails()
+}
+
+// refreshDetails starts reading the details of the hovered file, if it's changed since the last.
+func (m *model) refreshDetails() tea.Cmd {
+	if !m.detailing {
+		return nil
+	}
+
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.shown) {
+		m.detailsKey, m.details = "", nil
+		return nil
+	}
+
+	file := m.shown[cursor]
+	if file.String() == m.detailsKey {
+		return nil
+	}
+	m.detailsKey = file.String()
+
+	if f, ok := file.(folder); ok {
+		m.details = f.detailLines()
+		return nil
+	}
+
+	key := m.detailsKey
+	return func() tea.Msg {
+		return detailsMsg{key: key, details: files.Describe(newest(file))}
+	}
+}
+
+func (m model) detailsPane() string {
+	var (
+		width = m.termwidth - border
+		// at least as tall as the table it's in place of
+		height = lipgloss.Height(m.tableView())
+	)
+
+	if m.details == nil {
+		return style.Width(width).Height(height).Render(darkertext.Render("nothing to show"))
+	}
+
+	var labels, values []string
+	for _, line := range m.details {
+		labels = append(labels, line[0])
+		values = append(values, line[1])
+	}
+
+	labelWidth := 0
+	for _, label := range labels {
+		labelWidth = max(labelWidth, lipgloss.Width(label))
+	}
+	labelStyle := darktext.Width(labelWidth + poffset)
+	valueStyle := lipgloss.NewStyle().Width(max(width-labelWidth-poffset, 1))
+
+	rows := make([]string, 0, len(labels))
+	for i := range labels {
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render(labels[i]), valueStyle.Render(values[i])))
+	}
+
+	return style.Width(width).Height(height).Render(strings.Join(rows, "\n"))
+}
+
+// detailLines lays out details as label, value pairs, leaving out what doesn't apply.
+func detailLines(details files.Details) (lines [][2]string) {
+	add := func(label, value string) {
+		if value != "" {
+			lines = append(lines, [2]string{label, value})
+		}
+	}
+
+	add("name", details.Name)
+	add("original path", details.Path)
+	if details.ID != "" {
+		add("id", files.IDPrefix+details.ID)
+	}
+	add("trash", details.Trash)
+	add("trash path", details.TrashPath)
+	add("trashinfo", details.TrashInfo)
+	if !details.Trashed.IsZero() {
+		add("trashed", details.Trashed.Format(detailsDateFmt))
+	}
+
+	if details.Err != nil {
+		add("error", details.Err.Error())
+		return
+	}
+
+	add("modified", details.Modified.Format(detailsDateFmt))
+	add("permissions", details.Permissions)
+	if details.Owner != "" {
+		add("owner", details.Owner+":"+details.Group)
+	}
+	add("link to", details.Link)
+	add("size", fmt.Sprintf("%s (%d bytes)", humanize.Bytes(uint64(details.Size)), details.Size))
+	if details.IsDir {
+		add("entries", fmt.Sprint(details.Entries))
+	}
+	add("note", details.Note)
+	if len(details.Tags) > 0 {
+		add("tags", strings.Join(details.Tags, ", "))
+	}
+
+	return
+}
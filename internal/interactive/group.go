@@ -0,0 +1,105 @@
+package interactive
+
+import (
+	"io/fs"
+	"slices"
+	"time"
+
+	"git.burning.moe/celediel/gt/internal/files"
+)
+
+const (
+	collapsed string = "▸ "
+	expanded  string = "▾ "
+	indent    string = "   "
+)
+
+// group is a row standing in for several files, which can be expanded to show them.
+type group struct {
+	name, path string
+	children   files.Files
+}
+
+func (g group) Name() string      { return g.name }
+func (g group) Path() string      { return g.path }
+func (g group) IsDir() bool       { return g.children[0].IsDir() }
+func (g group) Mode() fs.FileMode { return g.children[0].Mode() }
+func (g group) String() string    { return "group:" + g.path }
+
+func (g group) Date() time.Time {
+	var latest time.Time
+	for _, child := range g.children {
+		if child.Date().After(latest) {
+			latest = child.Date()
+		}
+	}
+	return latest
+}
+
+func (g group) Filesize() int64 {
+	var size int64
+	for _, child := range g.children {
+		size += child.Filesize()
+	}
+	return size
+}
+
+// version is a file shown as one of its group's files.
+type version struct {
+	files.File
+	number int
+}
+
+// newest returns the file a row is showing, or the newest version if it's a group.
+func newest(file files.File) files.File {
+	switch f := file.(type) {
+	case group:
+		return f.children[len(f.children)-1]
+	case version:
+		return f.File
+	}
+	return file
+}
+
+func isGroup(file files.File) bool {
+	_, ok := file.(group)
+	return ok
+}
+
+// groupVersions puts files trashed from the same path together into a group, in the
+// place of the first of them, with its children sorted oldest first.
+func groupVersions(fls files.Files) files.Files {
+	var (
+		out    files.Files
+		byPath = map[string]files.Files{}
+	)
+
+	for _, file := range fls {
+		byPath[file.Path()] = append(byPath[file.Path()], file)
+	}
+
+	for _, file := range fls {
+		versions := byPath[file.Path()]
+		switch {
+		case len(versions) == 1:
+			out = append(out, file)
+		case versions[0].String() == file.String():
+			children := slices.Clone(versions)
+			slices.SortStableFunc(children, files.SortByModifiedReverse)
+			out = append(out, group{name: file.Name(), path: file.Path(), children: children})
+		}
+	}
+
+	return out
+}
+
+// versionNumbers numbers each file by when it was trashed, among the files trashed from the same path.
+func versionNumbers(fls files.Files) map[string]int {
+	numbers := map[string]int{}
+	for _, versions := range files.Versions(fls) {
+		for i, file := range versions {
+			numbers[file.String()] = i + 1
+		}
+	}
+	return numbers
+}
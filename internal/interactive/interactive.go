@@ -3,7 +3,7 @@ package interactive
 
 import (
 	"fmt"
-	"math"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"slices"
@@ -16,7 +16,9 @@ import (
 	"golang.org/x/term"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dustin/go-humanize"
@@ -26,50 +28,32 @@ import (
 const (
 	uncheck string = "☐"
 	check   string = "☑"
+	partial string = "◩"
 	space   string = " "
-	woffset int    = 13 // why this number, I don't know
 	hoffset int    = 6
 	poffset int    = 2
-
-	filenameColumn string = "filename"
-	pathColumn     string = "path"
-	modifiedColumn string = "modified"
-	trashedColumn  string = "trashed"
-	sizeColumn     string = "size"
-	bar            string = "───"
-
-	// TODO: figure these out dynamically based on longest of each
-	filenameColumnW float64 = 0.46
-	pathColumnW     float64 = 0.25
-	dateColumnW     float64 = 0.15
-	sizeColumnW     float64 = 0.12
-	checkColumnW    float64 = 0.02
-
-	// TODO: make these configurable or something
-	borderbg    string = "5"
-	hoveritembg string = "13"
-	black       string = "0"
-	darkblack   string = "8"
-	white       string = "7"
-	darkgray    string = "15"
+	border  int    = 2 // the rounded border's width, or height, around the table and panes
+	bar     string = "───"
 )
 
+// styles are set from the theme by applyTheme
 var (
-	style = lipgloss.NewStyle().
-		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color(borderbg))
-	regulartext = lipgloss.NewStyle().
+	style         lipgloss.Style
+	headertext    lipgloss.Style
+	selectedrow   lipgloss.Style
+	checkedrow    lipgloss.Style
+	directoryname lipgloss.Style
+	darktext      lipgloss.Style
+	darkertext    lipgloss.Style
+	darkesttext   lipgloss.Style
+	regulartext   = lipgloss.NewStyle().
 			Padding(0, poffset)
-	darktext = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(darkgray))
-	darkertext = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(darkblack))
-	darkesttext = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(black))
 )
 
 type model struct {
 	table      table.Model
+	columns    []table.Column
+	visible    []column
 	keys       keyMap
 	selected   map[string]bool
 	selectsize int64
@@ -85,11 +69,60 @@ type model struct {
 	workdir    string
 	files      files.Files
 	fltrfiles  files.Files
+	shown      files.Files
+	expanded   map[string]bool
+	versions   map[string]int
+	// tree is whether files are shown under the directories they were trashed from
+	tree bool
+	// visual is set while marking rows from anchor to the cursor, to select them all at once
+	visual bool
+	anchor int
+	// levels are the trashed directories gone into, outermost first
+	levels      []level
+	browsed     map[string]files.File
+	browseOrder []string
+	previewing  bool
+	preview     string
+	// previewKey is the hovered file and pane size preview was made for
+	previewKey string
+	detailing  bool
+	details    [][2]string
+	detailsKey string
+	// actions are set when files are restored and cleaned without quitting
+	actions  *Actions
+	pending  modes.Mode
+	running  modes.Mode
+	runCount int
+	spinner  spinner.Model
+	status   string
+	// reasking is set while asking again before cleaning, when asking to confirm
+	reasking bool
+	// quitAfter is set when quitting while an action runs, to quit once it's done
+	quitAfter bool
+	actionErr error
+	// conditions are set with the filter command, and files have to match all of them
+	conditions   []condition
+	commanding   bool
+	cmdline      textinput.Model
+	history      []string
+	historyIndex int
+	completions  []string
+	// offset is the first row showing in the table
+	offset   int
+	quitting bool
+	// shownColumns are the names of the columns shown, if they apply to the files
+	shownColumns []string
+	// infos are read from disk for columns that need more than the files know
+	infos map[string]fs.FileInfo
+	// owners are the names of the owners and groups in infos, by uid and gid
+	owners map[[2]uint32]string
 }
 
 func newModel(fls files.Files, selectall, readonly, once bool, workdir string, mode modes.Mode) model {
+	applyTheme()
+
 	m := model{
-		keys:       defaultKeyMap(),
+		keys:       keymap,
 		readonly:   readonly,
 		once:       once,
 		mode:       mode,
@@ -97,19 +130,25 @@ func newModel(fls files.Files, selectall, readonly, once bool, workdir string, m
 		selectsize: 0,
 		files:      fls,
 		totalsize:  fls.TotalSize(),
+		expanded:   map[string]bool{},
+		versions:   versionNumbers(fls),
+		tree:       treeShow,
+		browsed:    map[string]files.File{},
+		previewing: previewShow,
+		infos:      map[string]fs.FileInfo{},
+		owners:     map[[2]uint32]string{},
 	}
+	m.shownColumns = slices.Clone(shownColumns)
 
 	m.termwidth, m.termheight = termSizes()
+	m.termwidth -= poffset
 
 	if workdir != "" {
 		m.workdir = filepath.Clean(workdir)
 	}
 
-	rows := m.freshRows()
-	columns := m.freshColumns()
-
-	theight := min(m.termheight-hoffset, len(fls))
-	m.table = createTable(columns, rows, theight)
+	theight := min(m.tableHeight(), len(fls))
+	m.table = createTable(nil, nil, theight, m.keys.table)
 
 	m.sorting = sorting.Name
 	m.sort()
@@ -128,14 +167,30 @@ type keyMap struct {
 	nada key.Binding
 	invr key.Binding
 	rstr key.Binding
+	here key.Binding
 	clen key.Binding
+	open key.Binding
+	shut key.Binding
 	sort key.Binding
 	rort key.Binding
 	fltr key.Binding
+	cmnd key.Binding
+	prvw key.Binding
+	info key.Binding
+	tree key.Binding
+	rgup key.Binding
+	rgdn key.Binding
+	visl key.Binding
+	sdir key.Binding
+	slfl key.Binding
+	yes  key.Binding
+	no   key.Binding
 	clfl key.Binding
 	apfl key.Binding
 	bksp key.Binding
 	quit key.Binding
+	// table moves the cursor
+	table table.KeyMap
 }
 
 func defaultKeyMap() keyMap {
@@ -157,7 +212,7 @@ func defaultKeyMap() keyMap {
 			key.WithHelp("n", "none"),
 		),
 		invr: key.NewBinding(
-			key.WithKeys("i", "ctrl+i"),
+			key.WithKeys("i"),
 			key.WithHelp("i", "invert"),
 		),
 		clen: key.NewBinding(
@@ -168,6 +223,18 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "restore"),
 		),
+		here: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "restore here"),
+		),
+		open: key.NewBinding(
+			key.WithKeys("enter", "right"),
+			key.WithHelp("→", "open"),
+		),
+		shut: key.NewBinding(
+			key.WithKeys("backspace", "left"),
+			key.WithHelp("←", "close"),
+		),
 		sort: key.NewBinding(
 			key.WithKeys("s"),
 			key.WithHelp("s/S", "sort"),
@@ -180,6 +247,50 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("/"),
 			key.WithHelp("/", "filter"),
 		),
+		cmnd: key.NewBinding(
+			key.WithKeys(":"),
+			key.WithHelp(":", "command"),
+		),
+		prvw: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "preview"),
+		),
+		info: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "details"),
+		),
+		tree: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "tree"),
+		),
+		rgup: key.NewBinding(
+			key.WithKeys("shift+up"),
+			key.WithHelp("shift+↑", "select up"),
+		),
+		rgdn: key.NewBinding(
+			key.WithKeys("shift+down"),
+			key.WithHelp("shift+↓", "select down"),
+		),
+		visl: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "visual"),
+		),
+		sdir: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "same dir"),
+		),
+		slfl: key.NewBinding(
+			key.WithKeys("ctrl+a"),
+			key.WithHelp("ctrl+a", "select matching"),
+		),
+		yes: key.NewBinding(
+			key.WithKeys("y", "enter"),
+			key.WithHelp("y", "yes"),
+		),
+		no: key.NewBinding(
+			key.WithKeys("n", "esc", "q"),
+			key.WithHelp("n", "no"),
+		),
 		apfl: key.NewBinding(
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "apply filter"),
@@ -196,12 +307,13 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("q", "ctrl+c"),
 			key.WithHelp("q", "quit"),
 		),
+		table: fixTableKeymap(),
 	}
 }
 
 func (m model) Init() tea.Cmd {
 	/* if m.onePage() {
-		m.table.SetStyles(makeUnselectedStyle())
+		m.quitting = true
 		m.unselectAll()
 		return tea.Quit
 	} */
@@ -209,6 +321,12 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	updated.scroll()
+	return updated, cmd
+}
+
+func (m model) update(msg tea.Msg) (model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	if m.once {
@@ -218,7 +336,60 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.updateTableSize()
+	case previewMsg:
+		if msg.key == m.previewKey {
+			m.preview = msg.text
+		}
+		return m, nil
+	case detailsMsg:
+		if msg.key == m.detailsKey {
+			m.details = detailLines(msg.details)
+		}
+		return m, nil
+	case spinner.TickMsg:
+		if m.running != 0 {
+			m.spinner, cmd = m.spinner.Update(msg)
+		}
+		return m, cmd
+	case actionMsg:
+		m.finish(msg)
+		if m.quitAfter {
+			return m.quit(true)
+		}
+		cmd = tea.Batch(m.refreshPreview(), m.refreshDetails())
+		return m, cmd
+	case tea.MouseMsg:
+		m.updateMouse(msg)
+		cmd = tea.Batch(m.refreshPreview(), m.refreshDetails())
+		return m, cmd
 	case tea.KeyMsg:
+		if m.running != 0 {
+			// quitting now could leave files moved aside to restore over them hidden
+			if key.Matches(msg, m.keys.quit) {
+				m.quitAfter = true
+			}
+			return m, nil
+		}
+		if m.pending != 0 {
+			switch {
+			case key.Matches(msg, m.keys.yes) && m.pending == modes.Cleaning && m.actions.Confirm && !m.reasking:
+				m.reasking = true
+			case key.Matches(msg, m.keys.yes):
+				m.reasking = false
+				cmd = m.run(m.pending)
+				return m, cmd
+			case key.Matches(msg, m.keys.no):
+				m.pending, m.reasking = 0, false
+			}
+			return m, nil
+		}
+
+		if m.commanding {
+			cmd = m.updateCommandLine(msg)
+			cmd = tea.Batch(cmd, m.refreshPreview(), m.refreshDetails())
+			return m, cmd
+		}
+
 		if m.filtering {
 			switch {
 			case key.Matches(msg, m.keys.clfl):
@@ -226,6 +397,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.filtering = false
 			case key.Matches(msg, m.keys.apfl):
 				m.filtering = false
+			case key.Matches(msg, m.keys.slfl):
+				m.selectFiltered()
+				return m, nil
 			case key.Matches(msg, m.keys.bksp):
 				if len(m.filter) > 0 {
 					m.filter = m.filter[:len(m.filter)-1]
@@ -234,16 +408,38 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.filter += msg.String()
 			}
 			m.applyFilter()
+			cmd = tea.Batch(m.refreshPreview(), m.refreshDetails())
 			return m, cmd
 		}
 
+		if m.visual {
+			switch {
+			case key.Matches(msg, m.keys.mark):
+				m.applyVisual()
+				return m, nil
+			case key.Matches(msg, m.keys.visl), key.Matches(msg, m.keys.clfl):
+				m.visual = false
+				return m, nil
+			}
+		}
+
 		switch {
 		case key.Matches(msg, m.keys.mark):
 			m.toggleItem(m.table.Cursor())
-		case key.Matches(msg, m.keys.doit):
-			if !m.readonly && m.mode != modes.Interactive && len(m.fltrfiles) > 1 {
-				return m.quit(false)
-			}
+		case key.Matches(msg, m.keys.rgup):
+			m.extendSelection(-1)
+		case key.Matches(msg, m.keys.rgdn):
+			m.extendSelection(1)
+		case key.Matches(msg, m.keys.visl):
+			m.startVisual()
+		case key.Matches(msg, m.keys.sdir):
+			m.selectSameDir()
+		case key.Matches(msg, m.keys.doit) && !m.readonly && m.mode != modes.Interactive && len(m.fltrfiles) > 1:
+			return m.quit(false)
+		case key.Matches(msg, m.keys.open):
+			m.open(m.table.Cursor())
+		case key.Matches(msg, m.keys.shut):
+			m.shut(m.table.Cursor())
 		case key.Matches(msg, m.keys.nada):
 			m.unselectAll()
 		case key.Matches(msg, m.keys.todo):
@@ -254,6 +450,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.execute(modes.Cleaning)
 		case key.Matches(msg, m.keys.rstr):
 			return m.execute(modes.Restoring)
+		case key.Matches(msg, m.keys.here):
+			if m.mode == modes.Restoring && !m.readonly && len(m.selected) > 0 {
+				m.mode = modes.RestoringHere
+				return m.quit(false)
+			}
+			return m.execute(modes.RestoringHere)
 		case key.Matches(msg, m.keys.sort):
 			m.sorting = m.sorting.Next()
 			m.sort()
@@ -262,6 +464,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.sort()
 		case key.Matches(msg, m.keys.fltr):
 			m.filtering = true
+		case key.Matches(msg, m.keys.cmnd):
+			m.openCommandLine()
+		case key.Matches(msg, m.keys.prvw):
+			cmd = m.togglePreview()
+		case key.Matches(msg, m.keys.info):
+			cmd = m.toggleDetails()
+		case key.Matches(msg, m.keys.tree):
+			m.toggleTree()
+		case key.Matches(msg, m.keys.clfl) && m.detailing:
+			m.detailing = false
 		case key.Matches(msg, m.keys.clfl):
 			if m.filter != "" {
 				m.filter = ""
@@ -273,7 +485,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	// pass events along to the table
-	m.table, cmd = m.table.Update(msg)
+	var tblcmd tea.Cmd
+	m.table, tblcmd = m.table.Update(msg)
+	cmd = tea.Batch(cmd, tblcmd, m.refreshPreview(), m.refreshDetails())
 	return m, cmd
 }
 
@@ -283,7 +497,24 @@ func (m model) View() string {
 		panels = append(panels, m.header())
 	}
 
-	panels = append(panels, style.Render(m.table.View()), m.footer())
+	tbl := style.Render(m.tableView())
+	switch {
+	case m.pending != 0:
+		tbl = m.dialog()
+	case m.detailing:
+		tbl = m.detailsPane()
+	case !m.previewing:
+	case m.previewOnRight():
+		tbl = lipgloss.JoinHorizontal(lipgloss.Top, tbl, m.previewPane())
+	default:
+		tbl = lipgloss.JoinVertical(lipgloss.Left, tbl, m.previewPane())
+	}
+
+	panels = append(panels, tbl)
+	if m.status != "" {
+		panels = append(panels, regulartext.Render(darktext.Render(m.status)))
+	}
+	panels = append(panels, m.footer())
 
 	return lipgloss.JoinVertical(lipgloss.Top,
 		panels...,
@@ -295,14 +526,35 @@ func (m model) showHelp() string {
 	if m.filter != "" {
 		filterText = fmt.Sprintf(" (%s)", m.filter)
 	}
+	if len(m.conditions) > 0 {
+		texts := make([]string, 0, len(m.conditions))
+		for _, c := range m.conditions {
+			texts = append(texts, c.text)
+		}
+		filterText += fmt.Sprintf(" [%s]", strings.Join(texts, " "))
+	}
 
 	keys := []string{
 		fmt.Sprintf("%s %s%s", darktext.Render(m.keys.fltr.Help().Key), darkertext.Render(m.keys.fltr.Help().Desc), filterText),
 		fmt.Sprintf("%s %s (%s)", darktext.Render(m.keys.sort.Help().Key), darkertext.Render(m.keys.sort.Help().Desc), m.sorting.String()),
+		styleKey(m.keys.tree),
+		styleKey(m.keys.cmnd),
+		styleKey(m.keys.prvw),
+		styleKey(m.keys.info),
 		styleKey(m.keys.quit),
 	}
 
+	if len(m.expanded) > 0 || len(m.levels) > 0 {
+		keys = append([]string{styleKey(m.keys.shut)}, keys...)
+	}
+	if slices.ContainsFunc(m.shown, isGroup) || slices.ContainsFunc(m.shown, isFolder) || slices.ContainsFunc(m.shown, canBrowse) {
+		keys = append([]string{styleKey(m.keys.open)}, keys...)
+	}
+
 	if !m.readonly {
+		if m.mode == modes.Restoring {
+			keys = append([]string{styleKey(m.keys.here)}, keys...)
+		}
 		if m.mode != modes.Interactive {
 			keys = append([]string{styleKey(m.keys.doit)}, keys...)
 		}
@@ -317,6 +569,7 @@ func (m model) header() string {
 		spacerWidth int
 		keys        = []string{
 			styleKey(m.keys.rstr),
+			styleKey(m.keys.here),
 			styleKey(m.keys.clen),
 		}
 		selectKeys = []string{
@@ -328,6 +581,10 @@ func (m model) header() string {
 			styleKey(m.keys.clfl),
 			styleKey(m.keys.apfl),
 		}
+		visualKeys = []string{
+			fmt.Sprintf("%s %s", darktext.Render(m.keys.mark.Help().Key), darkertext.Render("select")),
+			fmt.Sprintf("%s %s", darktext.Render(m.keys.visl.Help().Key), darkertext.Render("cancel")),
+		}
 		dot          = darkesttext.Render("•")
 		wideDot      = darkesttext.Render(" • ")
 		keysFmt      = strings.Join(keys, wideDot)
@@ -339,7 +596,14 @@ func (m model) header() string {
 
 	switch {
 	case m.filtering:
+		if !m.readonly {
+			filterFmt += wideDot + styleKey(m.keys.slfl)
+		}
 		right = fmt.Sprintf(" Filtering %s %s", dot, filterFmt)
+	case m.visual:
+		first, last := m.visualRange()
+		right = fmt.Sprintf(" Visual %s %s", dot, strings.Join(visualKeys, wideDot))
+		left = fmt.Sprintf("%d rows %s %s", last-first+1, dot, selectedSize)
 	case m.mode == modes.Interactive:
 		right = fmt.Sprintf(" %s %s %s", keysFmt, dot, selectFmt)
 		left = fmt.Sprintf("%d/%d %s %s", len(m.selected), len(m.fltrfiles), dot, selectedSize)
@@ -358,6 +622,10 @@ func (m model) header() string {
 		left = fmt.Sprintf("%d/%d %s %s", len(m.selected), len(m.fltrfiles), dot, selectedSize)
 	}
 
+	if len(m.levels) > 0 && !m.filtering {
+		right = fmt.Sprintf(" %s %s%s", darktext.Render(m.breadcrumb()), dot, right)
+	}
+
 	spacerWidth = (m.termwidth - lipgloss.Width(right) - lipgloss.Width(left)) + 1
 	if spacerWidth <= 0 {
 		spacerWidth = 1 // always at least one space
@@ -367,6 +635,12 @@ func (m model) header() string {
 }
 
 func (m model) footer() string {
+	if m.running != 0 {
+		return m.progress()
+	}
+	if m.commanding {
+		return m.commandLine()
+	}
 	return regulartext.Render(m.showHelp())
 }
 
@@ -376,7 +650,7 @@ func (m model) quit(unselectAll bool) (model, tea.Cmd) {
 	} else {
 		m.onlySelected()
 	}
-	m.table.SetStyles(makeUnselectedStyle())
+	m.quitting = true
 	return m, tea.Quit
 }
 
@@ -386,14 +660,31 @@ func (m model) execute(mode modes.Mode) (model, tea.Cmd) {
 		return m, cmd
 	}
 
+	if m.actions != nil {
+		cmd := m.act(mode)
+		return m, cmd
+	}
+
 	m.mode = mode
 	m.onlySelected()
-	m.table.SetStyles(makeUnselectedStyle())
+	m.quitting = true
 	return m, tea.Quit
 }
 
 func (m model) selectedFiles() (outfile files.Files) {
-	for _, file := range m.fltrfiles {
+	// entries in trashed directories first, deepest first, so
+	// they're done before any directory they're in
+	for i := len(m.browseOrder) - 1; i >= 0; i-- {
+		if m.selected[m.browseOrder[i]] {
+			outfile = append(outfile, m.browsed[m.browseOrder[i]])
+		}
+	}
+
+	top := m.fltrfiles
+	if len(m.levels) > 0 {
+		top = m.levels[0].files
+	}
+	for _, file := range top {
 		if m.selected[file.String()] {
 			outfile = append(outfile, file)
 		}
@@ -410,22 +701,52 @@ func (m model) selectedFiles() (outfile files.Files) {
 	return false
 } */
 
-func (m *model) freshRows() (rows []table.Row) {
-	for _, file := range m.files {
-		row := newRow(file, m.workdir)
+func (m *model) freshRows(visible []column) (rows []table.Row) {
+	for _, file := range m.shown {
+		row := make(table.Row, 0, len(visible)+1)
+		for _, c := range visible {
+			if isFolder(file) && !c.folders {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, c.value(m, file))
+		}
 
 		if !m.readonly {
-			row = append(row, getCheck(false))
+			row = append(row, m.checkFor(file))
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) < 1 {
+		row := table.Row{"no files matched filter!"}
+		for range visible[1:] {
+			row = append(row, bar)
+		}
+		if !m.readonly {
+			row = append(row, uncheck)
 		}
 		rows = append(rows, row)
 	}
 	return
 }
 
+// refreshTable makes the rows for the files shown, and sizes the columns to fit them.
+func (m *model) refreshTable() {
+	m.visible = m.visibleColumns()
+	rows := m.freshRows(m.visible)
+	m.columns = m.fitColumns(m.visible, rows)
+
+	// the table draws the rows as they're set, so they can't have more cells than there are columns
+	m.table.SetRows(nil)
+	m.table.SetColumns(m.columns)
+	m.table.SetRows(rows)
+}
+
 func (m *model) onlySelected() {
 	var rows = make([]table.Row, 0)
-	for _, row := range m.table.Rows() {
-		if row[4] == check {
+	for index, row := range m.table.Rows() {
+		if index < len(m.shown) && m.isSelected(m.shown[index]) {
 			rows = append(rows, row)
 		} else {
 			rows = append(rows, table.Row{})
@@ -434,60 +755,53 @@ func (m *model) onlySelected() {
 	m.table.SetRows(rows)
 }
 
-// updateRow updates row of provided index with provided row.
-func (m *model) updateRow(index int, selected bool) {
-	rows := m.table.Rows()
-	row := rows[index]
-	rows[index] = table.Row{
-		row[0],
-		row[1],
-		row[2],
-		row[3],
-		getCheck(selected),
-	}
-
-	m.table.SetRows(rows)
+// updateRows updates the rows with the current selection.
+func (m *model) updateRows() {
+	m.refreshTable()
 }
 
-func (m *model) updateRows(selected bool) {
-	var newrows = []table.Row{}
+// isSelected checks if file, or any file in a group or folder, is selected.
+func (m *model) isSelected(file files.File) bool {
+	if isGroup(file) || isFolder(file) {
+		return slices.ContainsFunc(leaves(file), m.isSelected)
+	}
+	return m.selected[file.String()]
+}
 
-	for _, row := range m.table.Rows() {
-		newRow := table.Row{
-			row[0],
-			row[1],
-			row[2],
-			row[3],
-			getCheck(selected),
+// setSelected selects or unselects file, or all the files in a group or folder.
+func (m *model) setSelected(file files.File, selected bool) {
+	if isGroup(file) || isFolder(file) {
+		for _, leaf := range leaves(file) {
+			m.setSelected(leaf, selected)
 		}
-		newrows = append(newrows, newRow)
+		return
+	}
+
+	name := file.String()
+	if m.selected[name] == selected {
+		return
+	}
+
+	if selected {
+		m.selected[name] = true
+		m.selectsize += file.Filesize()
+	} else {
+		delete(m.selected, name)
+		m.selectsize -= file.Filesize()
 	}
-	m.table.SetRows(newrows)
 }
 
 func (m *model) toggleItem(index int) (selected bool) {
-	if m.readonly || len(m.fltrfiles) == 0 {
+	if m.readonly || len(m.shown) == 0 {
 		return false
 	}
 
-	name := m.fltrfiles[index].String()
-	size := m.fltrfiles[index].Filesize()
-
-	// select the thing
-	if v, ok := m.selected[name]; v && ok {
-		// already selected
-		delete(m.selected, name)
-		selected = false
-		m.selectsize -= size
-	} else {
-		// not selected
-		m.selected[name] = true
-		selected = true
-		m.selectsize += size
-	}
+	file := m.shown[index]
+	selected = m.checkFor(file) != check
+	m.setSelected(file, selected)
 
 	// update the rows with the state
-	m.updateRow(index, selected)
+	m.updateRows()
 	return
 }
 
@@ -496,13 +810,10 @@ func (m *model) selectAll() {
 		return
 	}
 
-	m.selected = map[string]bool{}
-	m.selectsize = 0
-	for i := range m.table.Rows() {
-		m.selected[m.fltrfiles[i].String()] = true
-		m.selectsize += m.fltrfiles[i].Filesize()
+	for _, file := range m.fltrfiles {
+		m.setSelected(file, true)
 	}
-	m.updateRows(true)
+	m.updateRows()
 }
 
 func (m *model) unselectAll() {
@@ -512,7 +823,7 @@ func (m *model) unselectAll() {
 
 	m.selected = map[string]bool{}
 	m.selectsize = 0
-	m.updateRows(false)
+	m.updateRows()
 }
 
 func (m *model) invertSelection() {
@@ -520,35 +831,71 @@ func (m *model) invertSelection() {
 		return
 	}
 
-	var newrows []table.Row
+	for _, file := range m.fltrfiles {
+		m.setSelected(file, !m.selected[file.String()])
+	}
+	m.updateRows()
+}
 
-	for index, row := range m.table.Rows() {
-		name := m.fltrfiles[index].String()
-		size := m.fltrfiles[index].Filesize()
-		if v, ok := m.selected[name]; v && ok {
-			delete(m.selected, name)
-			m.selectsize -= size
-			newrows = append(newrows, table.Row{
-				row[0],
-				row[1],
-				row[2],
-				row[3],
-				getCheck(false),
-			})
-		} else {
-			m.selected[name] = true
-			m.selectsize += size
-			newrows = append(newrows, table.Row{
-				row[0],
-				row[1],
-				row[2],
-				row[3],
-				getCheck(true),
-			})
+// open expands the group or folder at index, or goes into the trashed directory at index.
+func (m *model) open(index int) {
+	if len(m.shown) == 0 {
+		return
+	}
+
+	file := m.shown[index]
+	switch {
+	case isGroup(file) || isFolder(file):
+		if key := expandKey(file); !m.expanded[key] {
+			m.expanded[key] = true
+			m.applyFilter()
+		}
+	case canBrowse(file):
+		m.descend(index)
+	}
+}
+
+// shut collapses the group or folder at index, or the one the file at index is in,
+// or otherwise goes back out of the trashed directory being shown.
+func (m *model) shut(index int) {
+	var key string
+	if len(m.shown) > 0 {
+		file := m.shown[index]
+		switch file.(type) {
+		case group, folder:
+			key = expandKey(file)
+		case version:
+			key = file.Path()
 		}
+		if !m.expanded[key] && m.inTree() {
+			key = expandKey(folder{path: filepath.Dir(file.Path())})
+		}
+	}
+
+	if !m.expanded[key] {
+		if len(m.levels) > 0 {
+			m.ascend()
+		}
+		return
 	}
+	delete(m.expanded, key)
+	m.applyFilter()
 
-	m.table.SetRows(newrows)
+	// put the cursor back on the group or folder
+	for i, file := range m.shown {
+		if (isGroup(file) || isFolder(file)) && expandKey(file) == key {
+			m.table.SetCursor(i)
+			break
+		}
+	}
+}
+
+// expandKey is what a group or folder is remembered as being expanded by.
+func expandKey(file files.File) string {
+	if isFolder(file) {
+		return file.String()
+	}
+	return file.Path()
 }
 
 func (m *model) sort() {
@@ -557,30 +904,17 @@ func (m *model) sort() {
 }
 
 func (m *model) applyFilter() {
+	// rows move around, so visual mode's anchor wouldn't be where it was
+	m.visual = false
 	m.fltrfiles = m.filteredFiles()
-	var rows = []table.Row{}
-	for _, file := range m.fltrfiles {
-		row := newRow(file, m.workdir)
-		if !m.readonly {
-			row = append(row, getCheck(m.selected[file.String()]))
-		}
-		rows = append(rows, row)
-	}
-
-	if len(rows) < 1 {
-		row := table.Row{"no files matched filter!", bar, bar, bar}
-		if !m.readonly {
-			row = append(row, uncheck)
-		}
-		rows = append(rows, row)
-	}
-	m.table.SetRows(rows)
+	m.shown = m.visibleFiles()
+	m.refreshTable()
 	m.updateTableHeight()
 }
 
 func (m *model) filteredFiles() (filteredFiles files.Files) {
 	for _, file := range m.files {
-		if isMatch(m.filter, file.Name()) {
+		if isMatch(m.filter, file.Name()) && m.matchConditions(file) {
 			filteredFiles = append(filteredFiles, file)
 		} else {
 			if _, ok := m.selected[file.String()]; ok {
@@ -592,50 +926,90 @@ func (m *model) filteredFiles() (filteredFiles files.Files) {
 	return
 }
 
-func (m *model) freshColumns() []table.Column {
-	var (
-		fwidth     = int(math.Round(float64(m.termwidth-woffset) * filenameColumnW))
-		owidth     = int(math.Round(float64(m.termwidth-woffset) * pathColumnW))
-		dwidth     = int(math.Round(float64(m.termwidth-woffset) * dateColumnW))
-		swidth     = int(math.Round(float64(m.termwidth-woffset) * sizeColumnW))
-		cwidth     = int(math.Round(float64(m.termwidth-woffset) * checkColumnW))
-		datecolumn string
-	)
+// visibleFiles returns the filtered files as shown in the table, with files trashed
+// from the same path grouped together, and expanded groups followed by their files.
+// In the tree, they're in folders for the directories they were trashed from too.
+func (m *model) visibleFiles() (shown files.Files) {
+	top := groupVersions(m.fltrfiles)
+	if m.inTree() {
+		top = groupDirs(top, m.sorting.Sorter())
+	}
+	for _, file := range top {
+		shown = m.appendShown(shown, file)
+	}
+	return
+}
 
-	switch m.mode {
-	case modes.Trashing:
-		datecolumn = modifiedColumn
-	default:
-		datecolumn = trashedColumn
+// appendShown appends file to shown, followed by what's in it if it's expanded.
+func (m *model) appendShown(shown files.Files, file files.File) files.Files {
+	shown = append(shown, file)
+	switch file := file.(type) {
+	case folder:
+		if m.expanded[expandKey(file)] {
+			for _, child := range file.children {
+				shown = m.appendShown(shown, child)
+			}
+		}
+	case group:
+		if m.expanded[expandKey(file)] {
+			for _, child := range file.children {
+				shown = append(shown, version{File: child, number: m.versions[child.String()]})
+			}
+		}
 	}
+	return shown
+}
+
+// showNotes checks if any of the files have a note or tags to show.
+func (m *model) showNotes() bool {
+	return slices.ContainsFunc(m.topFiles(), func(file files.File) bool {
+		return noteFor(file) != ""
+	})
+}
 
-	columns := []table.Column{
-		{Title: filenameColumn, Width: fwidth},
-		{Title: pathColumn, Width: owidth},
-		{Title: datecolumn, Width: dwidth},
-		{Title: sizeColumn, Width: swidth},
+// checkFor returns the check for file, or partly checked if only some of a group's
+// or folder's files are selected.
+func (m *model) checkFor(file files.File) string {
+	if !isGroup(file) && !isFolder(file) {
+		if !m.selected[file.String()] && m.selectedInside(file) {
+			return partial
+		}
+		return getCheck(m.selected[file.String()])
 	}
 
-	if !m.readonly {
-		columns = append(columns, table.Column{Title: uncheck, Width: cwidth})
-	} else {
-		columns[0].Width += cwidth
+	var (
+		selected int
+		children = leaves(file)
+	)
+	for _, child := range children {
+		if m.selected[child.String()] {
+			selected++
+		}
 	}
 
-	return columns
+	switch {
+	case selected == 0 && slices.ContainsFunc(children, m.selectedInside):
+		return partial
+	case selected == 0:
+		return uncheck
+	case selected == len(children):
+		return check
+	default:
+		return partial
+	}
 }
 
 func (m *model) updateTableSize() {
 	width, height := termSizes()
 	m.termheight = height
 	m.termwidth = width - poffset
-	m.table.SetWidth(m.termwidth)
+	m.table.SetWidth(m.tableWidth())
 	m.updateTableHeight()
-	m.table.SetColumns(m.freshColumns())
+	m.refreshTable()
 }
 
 func (m *model) updateTableHeight() {
-	h := min(m.termheight-hoffset, len(m.table.Rows()))
+	h := min(m.tableHeight(), len(m.table.Rows()))
 	m.table.SetHeight(h)
 	if m.table.Cursor() >= h {
 		m.table.SetCursor(h - 1)
@@ -644,7 +1018,7 @@ func (m *model) updateTableHeight() {
 
 func Select(fls files.Files, selectall, once bool, workdir string, mode modes.Mode) (files.Files, modes.Mode, error) {
 	mdl := newModel(fls, selectall, false, once, workdir, mode)
-	endmodel, err := tea.NewProgram(mdl).Run()
+	endmodel, err := tea.NewProgram(mdl, programOptions()...).Run()
 	if err != nil {
 		return fls, 0, err
 	}
@@ -657,26 +1031,38 @@ func Select(fls files.Files, selectall, once bool, workdir string, mode modes.Mo
 
 func Show(fls files.Files, once bool, workdir string) error {
 	mdl := newModel(fls, false, true, once, workdir, modes.Listing)
-	if _, err := tea.NewProgram(mdl).Run(); err != nil {
+	if _, err := tea.NewProgram(mdl, programOptions()...).Run(); err != nil {
 		return err
 	}
 	return nil
 }
 
-func newRow(file files.File, workdir string) table.Row {
-	var time, size string
-	name := file.Name()
-	time = humanize.Time(file.Date())
-	if file.IsDir() {
-		name += string(os.PathSeparator)
+// shortID returns the short id of a trashed file, or nothing for other files.
+func shortID(file files.File) string {
+	if v, ok := file.(version); ok {
+		file = v.File
 	}
-	size = humanize.Bytes(uint64(file.Filesize()))
-	return table.Row{
-		dirs.PercentDecode(name),
-		dirs.UnExpand(filepath.Dir(file.Path()), workdir),
-		time,
-		size,
+	if t, ok := file.(files.TrashInfo); ok {
+		return t.ShortID()
 	}
+	return ""
+}
+
+// noteFor returns a trashed file's note followed by its tags, or nothing for other files.
+func noteFor(file files.File) string {
+	if v, ok := file.(version); ok {
+		file = v.File
+	}
+	t, ok := file.(files.TrashInfo)
+	if !ok {
+		return ""
+	}
+
+	note := t.Note()
+	for _, tag := range t.Tags() {
+		note = strings.TrimSpace(note + " #" + tag)
+	}
+	return note
 }
 
 func getCheck(selected bool) (ourcheck string) {
@@ -688,15 +1074,14 @@ func getCheck(selected bool) (ourcheck string) {
 	return
 }
 
-func createTable(columns []table.Column, rows []table.Row, height int) table.Model {
+func createTable(columns []table.Column, rows []table.Row, height int, keys table.KeyMap) table.Model {
 	tbl := table.New(
 		table.WithColumns(columns),
 		table.WithRows(rows),
 		table.WithFocused(true),
 		table.WithHeight(height),
 	)
-	tbl.KeyMap = fixTableKeymap()
-	tbl.SetStyles(makeStyle())
+	tbl.KeyMap = keys
 	return tbl
 }
 
@@ -713,30 +1098,6 @@ func fixTableKeymap() table.KeyMap {
 	return keys
 }
 
-func makeStyle() table.Styles {
-	style := table.DefaultStyles()
-	style.Header = style.Header.
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color(black)).
-		BorderBottom(true).
-		Bold(false)
-	style.Selected = style.Selected.
-		Foreground(lipgloss.Color(white)).
-		Background(lipgloss.Color(hoveritembg)).
-		Bold(false)
-
-	return style
-}
-
-func makeUnselectedStyle() table.Styles {
-	style := makeStyle()
-	style.Selected = style.Selected.
-		Foreground(lipgloss.NoColor{}).
-		Background(lipgloss.NoColor{}).
-		Bold(false)
-	return style
-}
-
 func styleKey(key key.Binding) string {
 	return fmt.Sprintf("%s %s", darktext.Render(key.Help().Key), darkertext.Render(key.Help().Desc))
 }
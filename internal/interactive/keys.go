@@ -0,0 +1,223 @@
+package interactive
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keymap is the keys the table is made with, set up by SetKeys.
+var keymap = defaultKeyMap()
+
+// presets are sets of keys to start from, as action = keys, like they're set in the config.
+var presets = map[string]map[string]string{
+	"default": {},
+	"vim": {
+		"toggle":         "space x",
+		"none":           "u",
+		"clean":          "d",
+		"open":           "l right enter",
+		"close":          "h left backspace",
+		"up":             "k up",
+		"down":           "j down",
+		"range-up":       "K shift+up",
+		"range-down":     "J shift+down",
+		"page-up":        "ctrl+b pgup",
+		"page-down":      "ctrl+f pgdown",
+		"half-page-up":   "ctrl+u",
+		"half-page-down": "ctrl+d",
+		"top":            "g home",
+		"bottom":         "G end",
+	},
+	"emacs": {
+		"toggle":         "m space",
+		"confirm":        "x enter",
+		"all":            "alt+a",
+		"none":           "U",
+		"invert":         "t",
+		"tree":           "T",
+		"visual":         "ctrl+@",
+		"select-matches": "alt+a",
+		"clean":          "D",
+		"open":           "enter ctrl+f right",
+		"close":          "^ ctrl+b left backspace",
+		"filter":         "ctrl+s",
+		"command":        "alt+x",
+		"clear-filter":   "ctrl+g esc",
+		"no":             "n ctrl+g esc q",
+		"up":             "ctrl+p up",
+		"down":           "ctrl+n down",
+		"page-up":        "alt+v pgup",
+		"page-down":      "ctrl+v pgdown",
+		"half-page-up":   "",
+		"half-page-down": "",
+		"top":            "alt+< home",
+		"bottom":         "alt+> end",
+	},
+}
+
+// keyContexts are the actions whose keys are used at the same time, so can't be the same.
+var keyContexts = [][]string{
+	{
+		"toggle", "confirm", "all", "none", "invert", "restore", "restore-here", "clean",
+		"open", "close", "sort", "sort-reverse", "filter", "command", "preview", "details",
+		"tree", "range-up", "range-down", "visual", "select-dir", "clear-filter", "quit", "up",
+		"down", "page-up", "page-down", "half-page-up", "half-page-down", "top", "bottom",
+	},
+	{"apply-filter", "clear-filter", "backspace", "select-matches"},
+	{"yes", "no"},
+}
+
+// sharedKeys are actions that can have the same keys, because the first only does anything
+// when choosing files to restore or clean, and the second only when it doesn't.
+var sharedKeys = [][2]string{{"confirm", "open"}}
+
+// actions are the names keys are set with, and the bindings they set.
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"toggle":         &k.mark,
+		"confirm":        &k.doit,
+		"all":            &k.todo,
+		"none":           &k.nada,
+		"invert":         &k.invr,
+		"restore":        &k.rstr,
+		"restore-here":   &k.here,
+		"clean":          &k.clen,
+		"open":           &k.open,
+		"close":          &k.shut,
+		"sort":           &k.sort,
+		"sort-reverse":   &k.rort,
+		"filter":         &k.fltr,
+		"command":        &k.cmnd,
+		"preview":        &k.prvw,
+		"details":        &k.info,
+		"tree":           &k.tree,
+		"range-up":       &k.rgup,
+		"range-down":     &k.rgdn,
+		"visual":         &k.visl,
+		"select-dir":     &k.sdir,
+		"select-matches": &k.slfl,
+		"yes":            &k.yes,
+		"no":             &k.no,
+		"apply-filter":   &k.apfl,
+		"clear-filter":   &k.clfl,
+		"backspace":      &k.bksp,
+		"quit":           &k.quit,
+		"up":             &k.table.LineUp,
+		"down":           &k.table.LineDown,
+		"page-up":        &k.table.PageUp,
+		"page-down":      &k.table.PageDown,
+		"half-page-up":   &k.table.HalfPageUp,
+		"half-page-down": &k.table.HalfPageDown,
+		"top":            &k.table.GotoTop,
+		"bottom":         &k.table.GotoBottom,
+	}
+}
+
+// SetKeys sets the keys used in the table, starting from preset, then changed by bindings
+// of action names to space separated keys, and checks none of them are used for two things.
+func SetKeys(preset string, bindings map[string]string) error {
+	if preset == "" {
+		preset = "default"
+	}
+	presetBindings, ok := presets[preset]
+	if !ok {
+		return fmt.Errorf("unknown key preset '%s' (possible values: default, vim, emacs)", preset)
+	}
+
+	keys := defaultKeyMap()
+	if err := keys.bind(presetBindings); err != nil {
+		return err
+	}
+	if err := keys.bind(bindings); err != nil {
+		return err
+	}
+	if err := keys.conflicts(); err != nil {
+		return err
+	}
+
+	keymap = keys
+	return nil
+}
+
+// bind sets the keys for each action in bindings.
+func (k *keyMap) bind(bindings map[string]string) error {
+	actions := k.actions()
+
+	for action, value := range bindings {
+		binding, ok := actions[action]
+		if !ok {
+			names := make([]string, 0, len(actions))
+			for name := range actions {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("can't set keys for unknown action '%s' (possible values: %s)", action, strings.Join(names, ", "))
+		}
+
+		var keys []string
+		for _, k := range strings.Fields(value) {
+			if k == "space" {
+				k = space
+			}
+			keys = append(keys, k)
+		}
+
+		binding.SetKeys(keys...)
+		binding.SetEnabled(len(keys) > 0)
+		if len(keys) > 0 {
+			binding.SetHelp(keyName(keys[0]), binding.Help().Desc)
+		}
+	}
+
+	// sort's help is for both ways of sorting
+	if len(k.sort.Keys()) > 0 && len(k.rort.Keys()) > 0 {
+		k.sort.SetHelp(keyName(k.sort.Keys()[0])+"/"+keyName(k.rort.Keys()[0]), k.sort.Help().Desc)
+	}
+
+	return nil
+}
+
+// conflicts returns an error if any key is used for more than one thing at the same time.
+func (k *keyMap) conflicts() error {
+	actions := k.actions()
+
+	for _, context := range keyContexts {
+		used := map[string]string{}
+		for _, action := range context {
+			for _, key := range actions[action].Keys() {
+				other, ok := used[key]
+				if ok && !slices.Contains(sharedKeys, [2]string{other, action}) {
+					return fmt.Errorf("key '%s' is set for both %s and %s", keyName(key), other, action)
+				}
+				used[key] = action
+			}
+		}
+	}
+
+	return nil
+}
+
+// keyName is how key is shown in help.
+func keyName(key string) string {
+	switch key {
+	case space:
+		return "space"
+	case "right":
+		return "→"
+	case "left":
+		return "←"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "shift+up":
+		return "shift+↑"
+	case "shift+down":
+		return "shift+↓"
+	default:
+		return key
+	}
+}
@@ -0,0 +1,81 @@
+package interactive
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	t.Cleanup(func() { keymap = defaultKeyMap() })
+
+	for preset := range presets {
+		if err := SetKeys(preset, nil); err != nil {
+			t.Fatalf("expected the %s preset to work as it is, got %v", preset, err)
+		}
+	}
+
+	if err := SetKeys("vim", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := keymap.clen.Keys(); !slices.Equal(got, []string{"d"}) {
+		t.Fatalf("expected vim to clean with d, got %v", got)
+	}
+	// what the preset doesn't set stays as it was
+	if got, want := keymap.rstr.Keys(), defaultKeyMap().rstr.Keys(); !slices.Equal(got, want) {
+		t.Fatalf("expected vim to restore with %v, got %v", want, got)
+	}
+
+	if err := SetKeys("emacs", nil); err != nil {
+		t.Fatal(err)
+	}
+	if keymap.table.HalfPageUp.Enabled() {
+		t.Fatal("expected an empty value to turn half-page-up off")
+	}
+	if got := keymap.shut.Help().Key; got != "^" {
+		t.Fatalf("expected close's help to show its first key, got %s", got)
+	}
+
+	if err := SetKeys("helix", nil); err == nil || !strings.Contains(err.Error(), "emacs") {
+		t.Fatalf("expected an unknown preset to list the possible ones, got %v", err)
+	}
+}
+
+func TestSetKeys(t *testing.T) {
+	t.Cleanup(func() { keymap = defaultKeyMap() })
+
+	if err := SetKeys("vim", map[string]string{"clean": "X", "toggle": "space"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := keymap.clen.Keys(); !slices.Equal(got, []string{"X"}) {
+		t.Fatalf("expected the config to win over the preset, got %v", got)
+	}
+	if got := keymap.mark.Keys(); !slices.Equal(got, []string{space}) {
+		t.Fatalf("expected space to be spelt out, got %q", got)
+	}
+
+	// confirm is only used choosing files, and open only browsing them
+	if err := SetKeys("", map[string]string{"confirm": "enter", "open": "enter"}); err != nil {
+		t.Fatal(err)
+	}
+	// yes and no are only used in the dialog, so can be keys the table uses
+	if err := SetKeys("", map[string]string{"yes": "r"}); err != nil {
+		t.Fatal(err)
+	}
+
+	before := keymap
+	for bindings, want := range map[string]string{
+		"clean=r":                "both restore and clean",
+		"apply-filter=backspace": "both apply-filter and backspace",
+		"explode=e":              "unknown action 'explode'",
+	} {
+		action, keys, _ := strings.Cut(bindings, "=")
+		err := SetKeys("", map[string]string{action: keys})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %s to fail with '%s', got %v", bindings, want, err)
+		}
+	}
+	if !slices.Equal(keymap.clen.Keys(), before.clen.Keys()) {
+		t.Fatal("expected keys that didn't work not to change anything")
+	}
+}
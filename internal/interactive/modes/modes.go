@@ -9,6 +9,8 @@ const (
 	Restoring
 	Cleaning
 	Interactive
+	RestoringHere
+	Tagging
 )
 
 func (m Mode) String() string {
@@ -23,6 +25,10 @@ func (m Mode) String() string {
 		return "Cleaning"
 	case Interactive:
 		return "Interactive"
+	case RestoringHere:
+		return "Restoring here"
+	case Tagging:
+		return "Tagging"
 	default:
 		return "0"
 	}
@@ -0,0 +1,115 @@
+package interactive
+
+import (
+	"slices"
+
+	"git.burning.moe/celediel/gt/internal/interactive/sorting"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// wheelLines is how many rows the mouse wheel moves the cursor.
+const wheelLines int = 3
+
+// mouseEnabled is whether the table uses the mouse, set up by SetMouse.
+var mouseEnabled = true
+
+// SetMouse sets whether the table can be clicked and scrolled with the mouse, which
+// keeps the terminal from selecting text with it.
+func SetMouse(enabled bool) {
+	mouseEnabled = enabled
+}
+
+// programOptions are the options tables are run with.
+func programOptions() (options []tea.ProgramOption) {
+	if mouseEnabled {
+		options = append(options, tea.WithMouseCellMotion())
+	}
+	return
+}
+
+// updateMouse moves the cursor to the row clicked, toggling it if the click was on its
+// check, sorts by the column whose title was clicked, and scrolls with the wheel.
+func (m *model) updateMouse(msg tea.MouseMsg) {
+	if m.running != 0 || m.pending != 0 || m.commanding || m.detailing {
+		return
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.table.MoveUp(wheelLines)
+		return
+	case tea.MouseButtonWheelDown:
+		m.table.MoveDown(wheelLines)
+		return
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return
+		}
+	default:
+		return
+	}
+
+	var (
+		top    = m.tableTop()
+		column = m.columnAt(msg.X)
+	)
+	switch {
+	case column < 0:
+	case msg.Y == top:
+		m.sortByColumn(column)
+	case msg.Y >= top+2:
+		index := m.offset + msg.Y - top - 2
+		if index >= len(m.shown) || index >= m.offset+m.table.Height() {
+			return
+		}
+		m.table.SetCursor(index)
+		if !m.readonly && column == len(m.columns)-1 {
+			m.toggleItem(index)
+		}
+	}
+}
+
+// tableTop is the line the column titles are on, under the header and the table's border.
+func (m model) tableTop() int {
+	top := 1
+	if !m.once {
+		top += lipgloss.Height(m.header())
+	}
+	return top
+}
+
+// columnAt is the index of the column at x, or -1 if there isn't one there.
+func (m model) columnAt(x int) int {
+	// past the table's border
+	start := 1
+	for i, c := range m.columns {
+		// and each column's padding
+		end := start + c.Width + 2
+		if x >= start && x < end {
+			return i
+		}
+		start = end
+	}
+	return -1
+}
+
+// sortByColumn sorts by what the column at index shows, or the other way if it already is.
+func (m *model) sortByColumn(index int) {
+	if index >= len(m.visible) || len(m.visible[index].sorts) == 0 {
+		return
+	}
+
+	sorts := m.visible[index].sorts
+	if slices.Contains(sorts, m.sorting.Field()) {
+		m.sorting = m.sorting.Reverse()
+	} else {
+		s, err := sorting.Parse(sorts[0], "")
+		if err != nil {
+			return
+		}
+		m.sorting = s
+	}
+	m.sort()
+}
@@ -0,0 +1,75 @@
+package interactive
+
+import (
+	"testing"
+	"time"
+
+	"git.burning.moe/celediel/gt/internal/files"
+	"git.burning.moe/celediel/gt/internal/interactive/modes"
+	"git.burning.moe/celediel/gt/internal/interactive/sorting"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// click sends a left click at x and y to m.
+func click(m model, x, y int) model {
+	updated, _ := m.Update(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	return updated.(model)
+}
+
+// middle is the x in the middle of column index, counting the table's border and
+// each column's padding.
+func middle(m model, index int) int {
+	x := 1
+	for _, c := range m.columns[:index] {
+		x += c.Width + 2
+	}
+	return x + 1 + m.columns[index].Width/2
+}
+
+func TestClicks(t *testing.T) {
+	var (
+		now = time.Now()
+		fls = files.Files{
+			fake{path: "/d/a", size: 30, date: now},
+			fake{path: "/d/b", size: 10, date: now.Add(-time.Hour)},
+			fake{path: "/d/c", size: 20, date: now.Add(-2 * time.Hour)},
+		}
+		updated, _ = newModel(fls, false, false, false, "", modes.Interactive).Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+		m          = updated.(model)
+		top        = m.tableTop()
+	)
+
+	size := -1
+	for i, c := range m.visible {
+		if c.name == "size" {
+			size = i
+		}
+	}
+
+	m = click(m, middle(m, size), top)
+	if m.sorting != sorting.Size || m.shown[0].Path() != "/d/b" {
+		t.Fatalf("expected clicking size's title to sort by size, got %s with %s first", m.sorting, m.shown[0].Path())
+	}
+	m = click(m, middle(m, size), top)
+	if m.sorting != sorting.SizeReverse || m.shown[0].Path() != "/d/a" {
+		t.Fatalf("expected clicking it again to sort the other way, got %s with %s first", m.sorting, m.shown[0].Path())
+	}
+
+	// the rows start under the titles and the line under them
+	m = click(m, middle(m, 0), top+3)
+	if m.table.Cursor() != 1 || len(m.selectedFiles()) != 0 {
+		t.Fatalf("expected clicking a name to only move the cursor, got row %d", m.table.Cursor())
+	}
+	check := len(m.columns) - 1
+	m = click(m, middle(m, check), top+4)
+	if m.table.Cursor() != 2 || !m.isSelected(m.shown[2]) {
+		t.Fatalf("expected clicking a check to select its row, got row %d", m.table.Cursor())
+	}
+
+	for _, x := range []int{0, middle(m, check) + m.columns[check].Width + 2} {
+		if got := m.columnAt(x); got != -1 {
+			t.Fatalf("expected no column at %d, past the table, got %d", x, got)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package interactive
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"git.burning.moe/celediel/gt/internal/files"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	previewRight  string = "right"
+	previewBottom string = "bottom"
+	previewAuto   string = "auto"
+
+	// previewW is how much of the width the preview pane takes when it's on the right
+	previewW float64 = 0.4
+	// previewH is how much of the height it takes when it's at the bottom
+	previewH float64 = 0.35
+	// previewMinWidth is how wide the terminal needs to be for the preview pane to go on the right in auto
+	previewMinWidth int = 120
+	previewTimeout      = 2 * time.Second
+	tabWidth        int = 4
+	hexBytesPerLine int = 16
+)
+
+var (
+	previewShow     bool
+	previewPosition = previewAuto
+	previewCommand  string
+)
+
+// SetPreview sets whether the preview pane starts open, where it goes, and the
+// command used to make previews, if any, instead of the built in ones.
+func SetPreview(show bool, position, command string) error {
+	switch position {
+	case previewRight, previewBottom, previewAuto:
+	case "":
+		position = previewAuto
+	default:
+		return fmt.Errorf("unknown preview position '%s' (possible values: right, bottom, auto)", position)
+	}
+	previewShow, previewPosition, previewCommand = show, position, command
+	return nil
+}
+
+// previewMsg is the preview of the file with key, once it's been made.
+type previewMsg struct {
+	key, text string
+}
+
+// previewOnRight checks if the preview pane goes beside the table rather than under it.
+func (m model) previewOnRight() bool {
+	switch previewPosition {
+	case previewRight:
+		return true
+	case previewBottom:
+		return false
+	default:
+		return m.termwidth >= previewMinWidth
+	}
+}
+
+func (m model) previewWidth() int {
+	if m.previewOnRight() {
+		return int(math.Round(float64(m.termwidth) * previewW))
+	}
+	return m.termwidth
+}
+
+func (m model) previewHeight() int {
+	if m.previewOnRight() {
+		// as tall as the table, border and all
+		return lipgloss.Height(m.tableView()) + border
+	}
+	return max(int(math.Round(float64(m.termheight)*previewH)), 1)
+}
+
+// tableWidth is how wide the table can be, next to the preview pane if it's showing.
+func (m model) tableWidth() int {
+	if m.previewing && m.previewOnRight() {
+		return m.termwidth - m.previewWidth() - poffset
+	}
+	return m.termwidth
+}
+
+// tableHeight is how tall the table can be, above the preview pane if it's showing.
+func (m model) tableHeight() int {
+	height := m.termheight - hoffset
+	if m.status != "" {
+		// and the status line
+		height--
+	}
+	if m.previewing && !m.previewOnRight() {
+		height -= m.previewHeight()
+	}
+	return height
+}
+
+// togglePreview shows or hides the preview pane, and makes room for it.
+func (m *model) togglePreview() tea.Cmd {
+	m.previewing = !m.previewing
+	m.previewKey = ""
+	m.updateTableSize()
+	return m.refreshPreview()
+}
+
+// refreshPreview starts making a preview of the hovered file, if it's changed since the last.
+func (m *model) refreshPreview() tea.Cmd {
+	if !m.previewing {
+		return nil
+	}
+
+	var file files.File
+	if cursor := m.table.Cursor(); cursor >= 0 && cursor < len(m.shown) {
+		file = m.shown[cursor]
+	}
+
+	width, height := m.previewSize()
+	key := fmt.Sprintf("%dx%d", width, height)
+	if file != nil {
+		key += file.String()
+	}
+	if key == m.previewKey {
+		return nil
+	}
+	m.previewKey = key
+
+	if file == nil {
+		m.preview = ""
+		return nil
+	}
+
+	return func() tea.Msg {
+		return previewMsg{key: key, text: makePreview(file, width, height)}
+	}
+}
+
+// previewSize is how much room there is for the preview inside the pane's border.
+func (m model) previewSize() (width, height int) {
+	return m.previewWidth() - border, m.previewHeight() - border
+}
+
+func (m model) previewPane() string {
+	width, height := m.previewSize()
+	return style.
+		Width(width).
+		Height(height).
+		Render(lipgloss.NewStyle().MaxWidth(width).MaxHeight(height).Render(m.preview))
+}
+
+// makePreview returns up to height lines of what's in file, with the external
+// previewer if there is one, or otherwise depending on what kind of file it is.
+func makePreview(file files.File, width, height int) string {
+	if f, ok := file.(folder); ok {
+		return f.preview(height)
+	}
+
+	path := files.Location(newest(file))
+	if path == "" {
+		return ""
+	}
+
+	if previewCommand != "" {
+		return externalPreview(path, width, height)
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err.Error()
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err.Error()
+		}
+		return "→ " + escape(target)
+	case info.IsDir():
+		return dirPreview(path, height)
+	case !info.Mode().IsRegular():
+		return fmt.Sprintf("%s (%s)", info.Mode().Type(), info.Mode())
+	default:
+		return filePreview(path, width, height)
+	}
+}
+
+// dirPreview lists up to height of the files in the directory at path.
+func dirPreview(path string, height int) string {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err.Error()
+	}
+	if len(entries) == 0 {
+		return darkertext.Render("empty directory")
+	}
+
+	var lines []string
+	for _, entry := range entries[:min(len(entries), height)] {
+		name := escape(entry.Name())
+		switch {
+		case entry.IsDir():
+			name += "/"
+		case entry.Type()&os.ModeSymlink != 0:
+			name += "@"
+		}
+		lines = append(lines, name)
+	}
+	if len(entries) > height {
+		lines[len(lines)-1] = darkertext.Render("…")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// filePreview shows the first lines of the file at path, or a hex dump of it if it's binary.
+func filePreview(path string, width, height int) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return err.Error()
+	}
+	defer f.Close()
+
+	// enough for the lines to fill the pane, in the worst case
+	head := make([]byte, max(width*height*utf8.UTFMax, hexBytesPerLine*height))
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err.Error()
+	}
+	head = head[:n]
+
+	if n == 0 {
+		return darkertext.Render("empty file")
+	}
+
+	if files.IsBinary(head) {
+		return strings.TrimSuffix(hex.Dump(head[:min(n, hexBytesPerLine*height)]), "\n")
+	}
+
+	lines := strings.Split(string(head), "\n")
+	for i, line := range lines[:min(len(lines), height)] {
+		lines[i] = escape(strings.TrimSuffix(line, "\r"))
+	}
+	return strings.Join(lines[:min(len(lines), height)], "\n")
+}
+
+// externalPreview runs the configured preview command for the file at path, replacing
+// {path}, {width}, and {height} in its arguments, or adding path to the end if there's no {path}.
+func externalPreview(path string, width, height int) string {
+	var (
+		args    = strings.Fields(previewCommand)
+		hasPath bool
+	)
+	for i, arg := range args {
+		if strings.Contains(arg, "{path}") {
+			hasPath = true
+		}
+		args[i] = strings.NewReplacer(
+			"{path}", path,
+			"{width}", strconv.Itoa(width),
+			"{height}", strconv.Itoa(height),
+		).Replace(arg)
+	}
+	if !hasPath {
+		args = append(args, path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), previewTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	text := strings.ReplaceAll(strings.TrimRight(string(out), "\n"), "\t", strings.Repeat(" ", tabWidth))
+	if err != nil && text == "" {
+		return err.Error()
+	}
+	return text
+}
+
+// escape replaces tabs with spaces, and control characters and invalid utf-8 with
+// escape sequences, so they can't mess up the terminal.
+func escape(text string) string {
+	out := strings.Builder{}
+	for i, r := range text {
+		switch {
+		case r == '\t':
+			out.WriteString(strings.Repeat(" ", tabWidth))
+		case r == utf8.RuneError && !strings.HasPrefix(text[i:], string(utf8.RuneError)):
+			out.WriteString(fmt.Sprintf(`\x%02x`, text[i]))
+		case unicode.IsControl(r) || !unicode.IsPrint(r) && !unicode.IsSpace(r):
+			quoted := strconv.QuoteRune(r)
+			out.WriteString(quoted[1 : len(quoted)-1])
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
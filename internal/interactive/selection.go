@@ -0,0 +1,105 @@
+package interactive
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"git.burning.moe/celediel/gt/internal/dirs"
+)
+
+// extendSelection selects the row at the cursor, moves the cursor by step, and selects
+// the row it moves to, so holding shift and moving selects everything passed over.
+func (m *model) extendSelection(step int) {
+	if m.readonly || len(m.shown) == 0 {
+		return
+	}
+
+	cursor := m.table.Cursor()
+	m.setSelected(m.shown[cursor], true)
+
+	next := max(min(cursor+step, len(m.shown)-1), 0)
+	m.table.SetCursor(next)
+	m.setSelected(m.shown[next], true)
+	m.updateRows()
+}
+
+// startVisual starts marking rows from the cursor to where it's moved.
+func (m *model) startVisual() {
+	if m.readonly || len(m.shown) == 0 {
+		return
+	}
+	m.visual = true
+	m.anchor = m.table.Cursor()
+}
+
+// visualRange is the first and last rows marked in visual mode.
+func (m model) visualRange() (first, last int) {
+	anchor := max(min(m.anchor, len(m.shown)-1), 0)
+	cursor := m.table.Cursor()
+	return min(anchor, cursor), max(anchor, cursor)
+}
+
+// inVisual checks if the row at index is marked in visual mode.
+func (m model) inVisual(index int) bool {
+	if !m.visual {
+		return false
+	}
+	first, last := m.visualRange()
+	return index >= first && index <= last
+}
+
+// applyVisual selects the rows marked in visual mode, or unselects them if they're all
+// selected already, and stops marking.
+func (m *model) applyVisual() {
+	m.visual = false
+	if len(m.shown) == 0 {
+		return
+	}
+
+	first, last := m.visualRange()
+	selected := true
+	for i := first; i <= last; i++ {
+		if m.checkFor(m.shown[i]) != check {
+			selected = false
+			break
+		}
+	}
+
+	for i := first; i <= last; i++ {
+		m.setSelected(m.shown[i], !selected)
+	}
+	m.updateRows()
+}
+
+// selectFiltered selects every file matching the filter being typed.
+func (m *model) selectFiltered() {
+	if m.readonly {
+		return
+	}
+	m.selectAll()
+	m.setStatus(fmt.Sprintf("selected %d files matching '%s'", len(m.fltrfiles), m.filter))
+}
+
+// selectSameDir selects every file trashed from the same directory as the file at the
+// cursor, or in the folder at the cursor.
+func (m *model) selectSameDir() {
+	if m.readonly || len(m.shown) == 0 {
+		return
+	}
+
+	hovered := m.shown[m.table.Cursor()]
+	dir := filepath.Dir(hovered.Path())
+	if isFolder(hovered) {
+		dir = hovered.Path()
+	}
+
+	var count int
+	for _, file := range m.fltrfiles {
+		if filepath.Dir(file.Path()) == dir {
+			m.setSelected(file, true)
+			count++
+		}
+	}
+	m.updateRows()
+	m.setStatus(fmt.Sprintf("selected %d files in %s", count, dirs.UnExpand(dir, "")))
+}
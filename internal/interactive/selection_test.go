@@ -0,0 +1,107 @@
+package interactive
+
+import (
+	"slices"
+	"testing"
+
+	"git.burning.moe/celediel/gt/internal/files"
+	"git.burning.moe/celediel/gt/internal/interactive/modes"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press sends keys to m like they were typed.
+func press(m model, keys ...tea.KeyMsg) model {
+	for _, k := range keys {
+		updated, _ := m.Update(k)
+		m = updated.(model)
+	}
+	return m
+}
+
+var (
+	up        = tea.KeyMsg{Type: tea.KeyUp}
+	down      = tea.KeyMsg{Type: tea.KeyDown}
+	shiftDown = tea.KeyMsg{Type: tea.KeyShiftDown}
+	spaceKey  = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(space)}
+	esc       = tea.KeyMsg{Type: tea.KeyEsc}
+	v         = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")}
+)
+
+// selectedRows are the rows of the table that are selected.
+func selectedRows(m model) (rows []int) {
+	for i, file := range m.shown {
+		if m.checkFor(file) == check {
+			rows = append(rows, i)
+		}
+	}
+	return
+}
+
+func TestVisualMode(t *testing.T) {
+	fls := files.Files{
+		fake{path: "/d/a"}, fake{path: "/d/b"}, fake{path: "/d/c"}, fake{path: "/d/d"}, fake{path: "/d/e"},
+	}
+	m := newModel(fls, false, false, false, "", modes.Interactive)
+
+	m = press(m, v, down, down)
+	if !m.visual || !m.inVisual(1) || m.inVisual(3) {
+		t.Fatalf("expected rows 0 to 2 marked, visual %t", m.visual)
+	}
+	if rows := selectedRows(m); len(rows) != 0 {
+		t.Fatalf("expected nothing selected until it's applied, got %v", rows)
+	}
+
+	m = press(m, spaceKey)
+	if m.visual {
+		t.Fatal("expected visual mode to stop once it's applied")
+	}
+	if rows := selectedRows(m); !slices.Equal(rows, []int{0, 1, 2}) {
+		t.Fatalf("expected rows 0 to 2 selected, got %v", rows)
+	}
+
+	// marking up from the cursor, over rows that are all selected, unselects them
+	m = press(m, v, up, spaceKey)
+	if rows := selectedRows(m); !slices.Equal(rows, []int{0}) {
+		t.Fatalf("expected only row 0 left selected, got %v", rows)
+	}
+
+	// marking over some that are selected selects the rest
+	m = press(m, up, v, down, down, spaceKey)
+	if rows := selectedRows(m); !slices.Equal(rows, []int{0, 1, 2}) {
+		t.Fatalf("expected rows 0 to 2 selected, got %v", rows)
+	}
+
+	// cancelling changes nothing
+	m = press(m, v, down, down, esc)
+	if rows := selectedRows(m); m.visual || !slices.Equal(rows, []int{0, 1, 2}) {
+		t.Fatalf("expected cancelling to leave rows 0 to 2 selected, got %v", rows)
+	}
+
+	// shift selects what the cursor passes over
+	m = press(m, up, up, shiftDown, shiftDown)
+	if rows := selectedRows(m); !slices.Equal(rows, []int{0, 1, 2, 3, 4}) || m.table.Cursor() != 4 {
+		t.Fatalf("expected everything selected with the cursor at the end, got %v at %d", rows, m.table.Cursor())
+	}
+
+	readonly := press(newModel(fls, false, true, false, "", modes.Interactive), v)
+	if readonly.visual {
+		t.Fatal("expected no visual mode when nothing can be selected")
+	}
+}
+
+func TestVisualStops(t *testing.T) {
+	fls := files.Files{fake{path: "/d/a"}, fake{path: "/e/b"}}
+
+	for name, change := range map[string]func(m *model){
+		"sorting":   func(m *model) { m.sorting = m.sorting.Reverse(); m.sort() },
+		"filtering": func(m *model) { m.filter = "a"; m.applyFilter() },
+		"the tree":  func(m *model) { m.toggleTree() },
+	} {
+		m := press(newModel(fls, false, false, false, "", modes.Interactive), v)
+		change(&m)
+		if m.visual {
+			t.Fatalf("expected %s to stop visual mode, since the rows move", name)
+		}
+	}
+}
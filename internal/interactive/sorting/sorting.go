@@ -1,7 +1,12 @@
 // Package sorting implements Sorting type for interactive table.
 package sorting
 
-import "git.burning.moe/celediel/gt/internal/files"
+import (
+	"fmt"
+	"strings"
+
+	"git.burning.moe/celediel/gt/internal/files"
+)
 
 type Sorting int
 
@@ -69,6 +74,42 @@ func (s Sorting) String() string {
 	}
 }
 
+// Field returns what s sorts by, as it's named for Parse.
+func (s Sorting) Field() string {
+	switch s {
+	case Name, NameReverse:
+		return "name"
+	case Date, DateReverse:
+		return "date"
+	case Path, PathReverse:
+		return "path"
+	case Size, SizeReverse:
+		return "size"
+	case Extension, ExtensionReverse:
+		return "extension"
+	case Directory, DirectoryReverse:
+		return "directories"
+	default:
+		return ""
+	}
+}
+
+// Arrow returns which way s sorts, as shown after its field.
+func (s Sorting) Arrow() string {
+	if s%2 == 0 {
+		return "↓"
+	}
+	return "↑"
+}
+
+// Reverse returns s sorting the other way.
+func (s Sorting) Reverse() Sorting {
+	if s%2 == 0 {
+		return s - 1
+	}
+	return s + 1
+}
+
 func (s Sorting) Sorter() func(a, b files.File) int {
 	switch s {
 	case Name:
@@ -99,3 +140,48 @@ func (s Sorting) Sorter() func(a, b files.File) int {
 		return files.SortByName
 	}
 }
+
+// Fields are the names of what files can be sorted by, for Parse.
+var Fields = []string{"name", "date", "path", "size", "extension", "directories"}
+
+// Directions are how files can be sorted, for Parse.
+var Directions = []string{"asc", "desc"}
+
+// Parse returns the Sorting by field, in direction asc or desc, or the way it's
+// first cycled to if direction is empty. Dates ascending are oldest first.
+func Parse(field, direction string) (Sorting, error) {
+	var s Sorting
+	switch field {
+	case "name":
+		s = Name
+	case "date":
+		s = Date
+	case "path":
+		s = Path
+	case "size":
+		s = Size
+	case "extension", "ext":
+		s = Extension
+	case "directories", "dirs":
+		s = Directory
+	default:
+		return 0, fmt.Errorf("can't sort by '%s' (possible values: %s)", field, strings.Join(Fields, ", "))
+	}
+
+	switch direction {
+	case "":
+		return s, nil
+	case "asc":
+		if s == Date {
+			return DateReverse, nil
+		}
+		return s, nil
+	case "desc":
+		if s == Date {
+			return Date, nil
+		}
+		return s + 1, nil
+	default:
+		return 0, fmt.Errorf("unknown sort direction '%s' (possible values: asc, desc)", direction)
+	}
+}
@@ -0,0 +1,65 @@
+package sorting_test
+
+import (
+	"strings"
+	"testing"
+
+	"git.burning.moe/celediel/gt/internal/interactive/sorting"
+)
+
+func TestParse(t *testing.T) {
+	for _, field := range sorting.Fields {
+		asc, err := sorting.Parse(field, "asc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc, err := sorting.Parse(field, "desc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if asc == desc {
+			t.Fatalf("expected %s asc and desc to differ, both were %s", field, asc)
+		}
+
+		// with no direction, it's whichever way it's first cycled to
+		plain, err := sorting.Parse(field, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plain != asc && plain != desc {
+			t.Fatalf("expected %s to be %s or %s, got %s", field, asc, desc, plain)
+		}
+	}
+
+	// dates are newest first, unless asked for ascending, oldest first
+	for direction, want := range map[string]sorting.Sorting{
+		"": sorting.Date, "desc": sorting.Date, "asc": sorting.DateReverse,
+	} {
+		if got, _ := sorting.Parse("date", direction); got != want {
+			t.Fatalf("expected date %s to be %s, got %s", direction, want, got)
+		}
+	}
+
+	if got, _ := sorting.Parse("ext", ""); got != sorting.Extension {
+		t.Fatalf("expected ext to be short for extension, got %s", got)
+	}
+
+	if _, err := sorting.Parse("colour", ""); err == nil || !strings.Contains(err.Error(), "extension") {
+		t.Fatalf("expected an unknown field to list the possible ones, got %v", err)
+	}
+	if _, err := sorting.Parse("name", "up"); err == nil {
+		t.Fatal("expected an unknown direction to be an error")
+	}
+}
+
+func TestReverse(t *testing.T) {
+	for s := sorting.Name; s <= sorting.DirectoryReverse; s++ {
+		reversed := s.Reverse()
+		if reversed.Field() != s.Field() || reversed.Arrow() == s.Arrow() {
+			t.Fatalf("expected %s reversed to sort by %s the other way, got %s", s, s.Field(), reversed)
+		}
+		if reversed.Reverse() != s {
+			t.Fatalf("expected %s reversed twice to be %s, got %s", s, s, reversed.Reverse())
+		}
+	}
+}
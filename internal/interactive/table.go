@@ -0,0 +1,128 @@
+package interactive
+
+import (
+	"strings"
+
+	"git.burning.moe/celediel/gt/internal/lscolors"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// The table's rows are drawn here rather than by the table itself, which cuts off styled
+// text in the wrong place, so checked rows and file names can be styled. The table still
+// keeps the rows and the cursor, and moves it.
+
+// nameColors color file names like ls does, if they're set.
+var nameColors lscolors.Colors
+
+// SetNameColors sets the colors file names are colored with, or none to not color them.
+func SetNameColors(colors lscolors.Colors) {
+	nameColors = colors
+}
+
+// scroll moves which rows are showing, so the cursor's always one of them.
+func (m *model) scroll() {
+	var (
+		height = m.table.Height()
+		cursor = m.table.Cursor()
+	)
+
+	if cursor < m.offset {
+		m.offset = cursor
+	}
+	if cursor >= m.offset+height {
+		m.offset = cursor - height + 1
+	}
+	m.offset = max(min(m.offset, len(m.table.Rows())-height), 0)
+}
+
+// tableView draws the column headers, and the rows showing, as tall as the table.
+func (m model) tableView() string {
+	var (
+		columns = m.columns
+		rows    = m.table.Rows()
+		height  = m.table.Height()
+		width   = m.table.Width()
+		offset  = max(min(m.offset, len(rows)-height), 0)
+		headers = make([]string, 0, len(columns))
+		lines   = make([]string, 0, height)
+	)
+
+	header := headertext.
+		Padding(0, 1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(darkesttext.GetForeground()).
+		BorderBottom(true)
+	for _, column := range columns {
+		headers = append(headers, header.Render(pad(runewidth.Truncate(column.Title, column.Width, "…"), column.Width)))
+	}
+
+	for i := offset; i < min(offset+height, len(rows)); i++ {
+		lines = append(lines, m.rowView(i, columns, rows[i]))
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+
+	body := strings.Join(lines, "\n")
+	if width > 0 {
+		body = lipgloss.NewStyle().Width(width).MaxWidth(width).Render(body)
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Left, headers...) + "\n" + body
+}
+
+// rowView draws the row at index, styled for whether it's hovered or checked, with the
+// file's name colored by LS_COLORS, or styled if it's a directory otherwise.
+func (m model) rowView(index int, columns []table.Column, row table.Row) string {
+	var (
+		cells   = make([]string, 0, len(row))
+		hovered = (index == m.table.Cursor() || m.inVisual(index)) && !m.quitting
+		rowtext = lipgloss.NewStyle()
+	)
+
+	var isDir bool
+	if index < len(m.shown) && len(row) > 0 {
+		isDir = m.shown[index].IsDir()
+		if !hovered && m.isSelected(m.shown[index]) {
+			rowtext = checkedrow
+		}
+	}
+
+	for i, value := range row {
+		if i >= len(columns) {
+			break
+		}
+		var (
+			text   = rowtext
+			isName = i < len(m.visible) && m.visible[i].name == "name"
+		)
+		value = runewidth.Truncate(value, columns[i].Width, "…")
+		switch {
+		case hovered:
+			text = lipgloss.NewStyle()
+		case isName && index < len(m.shown) && !nameColors.Empty():
+			value = nameColors.Paint(newest(m.shown[index]), value)
+		case isName && isDir:
+			text = directoryname.Inherit(rowtext)
+		}
+		cells = append(cells, text.Padding(0, 1).Render(pad(value, columns[i].Width)))
+	}
+
+	line := lipgloss.JoinHorizontal(lipgloss.Left, cells...)
+	if hovered {
+		return selectedrow.Render(line)
+	}
+	return line
+}
+
+// pad pads out value, already cut off to fit, to width.
+func pad(value string, width int) string {
+	return lipgloss.NewStyle().
+		Width(width).
+		MaxWidth(width).
+		Inline(true).
+		Render(value)
+}
@@ -0,0 +1,289 @@
+package interactive
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	backgroundAuto  string = "auto"
+	backgroundDark  string = "dark"
+	backgroundLight string = "light"
+)
+
+// palette is the colors of a theme, each either an ANSI color number or a hex color, or
+// nothing for the terminal's own colors.
+type palette struct {
+	border, header, selected, selectedBg, checked, directory, footer, footerKey, separator string
+}
+
+// builtinTheme is a theme's palettes for dark and light backgrounds, and anything else
+// it does besides colors.
+type builtinTheme struct {
+	dark, light palette
+	// attributes are added to the styles with the same names
+	attributes map[string][]string
+}
+
+// themeNames are the built in themes, in the order they're listed.
+var themeNames = []string{"default", "mono", "catppuccin", "gruvbox"}
+
+var themes = map[string]builtinTheme{
+	"default": {
+		dark: palette{
+			border:     "5",
+			selected:   "7",
+			selectedBg: "13",
+			checked:    "13",
+			directory:  "4",
+			footer:     "8",
+			footerKey:  "15",
+			separator:  "0",
+		},
+	},
+	// mono has no colors, only attributes, and is used when NO_COLOR is set
+	"mono": {
+		attributes: map[string][]string{
+			"header":    {"bold"},
+			"selected":  {"reverse"},
+			"checked":   {"italic"},
+			"directory": {"bold"},
+			"footer":    {"faint"},
+			"separator": {"faint"},
+		},
+	},
+	"catppuccin": {
+		dark: palette{
+			border:     "#cba6f7",
+			header:     "#bac2de",
+			selected:   "#1e1e2e",
+			selectedBg: "#cba6f7",
+			checked:    "#f5c2e7",
+			directory:  "#89b4fa",
+			footer:     "#7f849c",
+			footerKey:  "#a6adc8",
+			separator:  "#585b70",
+		},
+		light: palette{
+			border:     "#8839ef",
+			header:     "#5c5f77",
+			selected:   "#eff1f5",
+			selectedBg: "#8839ef",
+			checked:    "#ea76cb",
+			directory:  "#1e66f5",
+			footer:     "#8c8fa1",
+			footerKey:  "#6c6f85",
+			separator:  "#acb0be",
+		},
+	},
+	"gruvbox": {
+		dark: palette{
+			border:     "#fabd2f",
+			header:     "#ebdbb2",
+			selected:   "#282828",
+			selectedBg: "#fabd2f",
+			checked:    "#fe8019",
+			directory:  "#83a598",
+			footer:     "#928374",
+			footerKey:  "#d5c4a1",
+			separator:  "#504945",
+		},
+		light: palette{
+			border:     "#b57614",
+			header:     "#3c3836",
+			selected:   "#fbf1c7",
+			selectedBg: "#b57614",
+			checked:    "#af3a03",
+			directory:  "#076678",
+			footer:     "#928374",
+			footerKey:  "#504945",
+			separator:  "#d5c4a1",
+		},
+	},
+}
+
+// themeElements are the parts of the table that can be styled on their own.
+var themeElements = []string{
+	"border", "header", "selected", "checked", "directory", "footer", "footer-key", "separator",
+}
+
+var (
+	themeName       = "default"
+	themeBackground = backgroundAuto
+	themeStyles     = map[string]lipgloss.Style{}
+	themeApplied    bool
+)
+
+var (
+	attributeRegex = regexp.MustCompile(`^(bold|faint|italic|underline|reverse|strikethrough)$`)
+	hexRegex       = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+)
+
+// SetTheme sets the built in theme the table is drawn with, whether it's for a dark or
+// light background, or auto to ask the terminal, and styles to use instead of the theme's
+// for any of its elements. With NO_COLOR set, the mono theme is used to start from.
+func SetTheme(name, background string, styles map[string]string) error {
+	if name == "" {
+		name = "default"
+	}
+	if _, ok := themes[name]; !ok {
+		return fmt.Errorf("unknown theme '%s' (possible values: %s)", name, strings.Join(themeNames, ", "))
+	}
+
+	switch background {
+	case backgroundAuto, backgroundDark, backgroundLight:
+	case "":
+		background = backgroundAuto
+	default:
+		return fmt.Errorf("unknown background '%s' (possible values: auto, dark, light)", background)
+	}
+
+	parsed := map[string]lipgloss.Style{}
+	for element, spec := range styles {
+		if !slices.Contains(themeElements, element) {
+			return fmt.Errorf("can't style unknown element '%s' (possible values: %s)", element, strings.Join(themeElements, ", "))
+		}
+		style, err := parseStyle(spec)
+		if err != nil {
+			return fmt.Errorf("bad style for %s: %w", element, err)
+		}
+		parsed[element] = style
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		name = "mono"
+	}
+
+	themeName, themeBackground, themeStyles = name, background, parsed
+	themeApplied = false
+	return nil
+}
+
+// applyTheme sets the styles everything is drawn with from the theme, the first time
+// a table is made, so the terminal's only asked about its background when it's needed.
+func applyTheme() {
+	if themeApplied {
+		return
+	}
+	themeApplied = true
+
+	var (
+		theme  = themes[themeName]
+		colors = theme.dark
+	)
+	if theme.light != (palette{}) {
+		dark := lipgloss.HasDarkBackground()
+		if themeBackground != backgroundAuto {
+			dark = themeBackground == backgroundDark
+		}
+		if !dark {
+			colors = theme.light
+		}
+	}
+
+	elements := map[string]lipgloss.Style{
+		"border":     foreground(colors.border),
+		"header":     foreground(colors.header),
+		"selected":   foreground(colors.selected).Background(color(colors.selectedBg)),
+		"checked":    foreground(colors.checked),
+		"directory":  foreground(colors.directory),
+		"footer":     foreground(colors.footer),
+		"footer-key": foreground(colors.footerKey),
+		"separator":  foreground(colors.separator),
+	}
+	for element, attributes := range theme.attributes {
+		for _, attribute := range attributes {
+			elements[element] = withAttribute(elements[element], attribute)
+		}
+	}
+	for element, style := range themeStyles {
+		elements[element] = style
+	}
+
+	style = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(elements["border"].GetForeground())
+	headertext = elements["header"]
+	selectedrow = elements["selected"]
+	checkedrow = elements["checked"]
+	directoryname = elements["directory"]
+	darktext = elements["footer-key"]
+	darkertext = elements["footer"]
+	darkesttext = elements["separator"]
+}
+
+// parseStyle parses a style like "#ffffff on #ff00ff bold", made of a foreground color,
+// "on" and a background color, and attributes. Colors are ANSI color numbers, hex colors,
+// or none for the terminal's own color.
+func parseStyle(spec string) (lipgloss.Style, error) {
+	var (
+		style  = lipgloss.NewStyle()
+		fields = strings.Fields(spec)
+	)
+
+	for i := 0; i < len(fields); i++ {
+		field := strings.ToLower(fields[i])
+		switch {
+		case attributeRegex.MatchString(field):
+			style = withAttribute(style, field)
+		case field == "on":
+			if i+1 >= len(fields) || !isColor(fields[i+1]) {
+				return style, fmt.Errorf("expected a color after 'on' in '%s'", spec)
+			}
+			i++
+			style = style.Background(color(fields[i]))
+		case isColor(field):
+			style = style.Foreground(color(field))
+		default:
+			return style, fmt.Errorf("'%s' isn't a color or an attribute "+
+				"(possible values: 0-255, #rrggbb, none, bold, faint, italic, underline, reverse, strikethrough)", fields[i])
+		}
+	}
+
+	return style, nil
+}
+
+func isColor(value string) bool {
+	if value == "none" || hexRegex.MatchString(value) {
+		return true
+	}
+	n, err := strconv.Atoi(value)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// color is value as a lipgloss color, or no color for none or nothing.
+func color(value string) lipgloss.TerminalColor {
+	if value == "" || value == "none" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(value)
+}
+
+func foreground(value string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(color(value))
+}
+
+func withAttribute(style lipgloss.Style, attribute string) lipgloss.Style {
+	switch attribute {
+	case "bold":
+		return style.Bold(true)
+	case "faint":
+		return style.Faint(true)
+	case "italic":
+		return style.Italic(true)
+	case "underline":
+		return style.Underline(true)
+	case "reverse":
+		return style.Reverse(true)
+	case "strikethrough":
+		return style.Strikethrough(true)
+	default:
+		return style
+	}
+}
@@ -0,0 +1,73 @@
+package interactive
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestApplyTheme(t *testing.T) {
+	saved := []lipgloss.Style{style, headertext, selectedrow, checkedrow, directoryname, darktext, darkertext, darkesttext}
+	t.Cleanup(func() {
+		style, headertext, selectedrow, checkedrow = saved[0], saved[1], saved[2], saved[3]
+		directoryname, darktext, darkertext, darkesttext = saved[4], saved[5], saved[6], saved[7]
+		_ = SetTheme("", "", nil)
+	})
+	t.Setenv("NO_COLOR", "")
+
+	err := SetTheme("gruvbox", "light", map[string]string{"checked": "1 on #fff Bold"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	applyTheme()
+
+	if got := selectedrow.GetForeground(); got != lipgloss.Color("#fbf1c7") {
+		t.Fatalf("expected gruvbox's light selected color, got %v", got)
+	}
+	if got := directoryname.GetForeground(); got != lipgloss.Color("#076678") {
+		t.Fatalf("expected gruvbox's light directory color, got %v", got)
+	}
+	if checkedrow.GetForeground() != lipgloss.Color("1") || checkedrow.GetBackground() != lipgloss.Color("#fff") ||
+		!checkedrow.GetBold() {
+		t.Fatalf("expected the configured style for checked rows, got %v on %v",
+			checkedrow.GetForeground(), checkedrow.GetBackground())
+	}
+
+	// mono is only attributes, and used whatever's asked for when NO_COLOR is set
+	t.Setenv("NO_COLOR", "1")
+	if err := SetTheme("catppuccin", "dark", nil); err != nil {
+		t.Fatal(err)
+	}
+	applyTheme()
+	if selectedrow.GetForeground() != (lipgloss.NoColor{}) || !selectedrow.GetReverse() {
+		t.Fatalf("expected the selected row only reversed, got %v", selectedrow.GetForeground())
+	}
+	if !directoryname.GetBold() {
+		t.Fatal("expected directories in bold")
+	}
+}
+
+func TestSetThemeErrors(t *testing.T) {
+	t.Cleanup(func() { _ = SetTheme("", "", nil) })
+
+	for _, test := range []struct {
+		name, background string
+		styles           map[string]string
+		want             string
+	}{
+		{name: "solarized", want: "unknown theme 'solarized'"},
+		{background: "grey", want: "unknown background 'grey'"},
+		{styles: map[string]string{"cursor": "1"}, want: "unknown element 'cursor'"},
+		{styles: map[string]string{"header": "256"}, want: "bad style for header"},
+		{styles: map[string]string{"header": "#ff00f"}, want: "'#ff00f' isn't a color"},
+		{styles: map[string]string{"footer": "red"}, want: "'red' isn't a color"},
+		{styles: map[string]string{"footer": "1 on bold"}, want: "expected a color after 'on'"},
+		{styles: map[string]string{"footer": "blink"}, want: "'blink' isn't a color"},
+	} {
+		err := SetTheme(test.name, test.background, test.styles)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("expected an error with '%s', got %v", test.want, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package interactive
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"git.burning.moe/celediel/gt/internal/files"
+
+	"github.com/dustin/go-humanize"
+)
+
+// treeShow is whether the table starts showing files as a tree, set up by SetTree.
+var treeShow bool
+
+// SetTree sets whether the table starts with files in a tree, under the directories
+// they were trashed from.
+func SetTree(show bool) {
+	treeShow = show
+}
+
+// folder is a row in the tree standing in for the files trashed from the same
+// directory, which can be expanded to show them.
+type folder struct {
+	path     string
+	children files.Files
+}
+
+func (f folder) Name() string      { return filepath.Base(f.path) }
+func (f folder) Path() string      { return f.path }
+func (f folder) IsDir() bool       { return true }
+func (f folder) Mode() fs.FileMode { return fs.ModeDir }
+func (f folder) String() string    { return "folder:" + f.path }
+func (f folder) Filesize() int64   { return f.children.TotalSize() }
+
+func (f folder) Date() time.Time {
+	var latest time.Time
+	for _, child := range f.children {
+		if child.Date().After(latest) {
+			latest = child.Date()
+		}
+	}
+	return latest
+}
+
+// count is how many files are in the folder, counting each of a group's files.
+func (f folder) count() int {
+	return len(leaves(f))
+}
+
+// detailLines are what's shown about the folder in place of the table.
+func (f folder) detailLines() [][2]string {
+	return [][2]string{
+		{"directory", f.path},
+		{"files", fmt.Sprint(f.count())},
+		{"size", fmt.Sprintf("%s (%d bytes)", humanize.Bytes(uint64(f.Filesize())), f.Filesize())},
+		{"last trashed", f.Date().Format(detailsDateFmt)},
+	}
+}
+
+// preview lists up to height of the folder's files.
+func (f folder) preview(height int) string {
+	var lines []string
+	for _, file := range leaves(f) {
+		if len(lines) >= height {
+			break
+		}
+		lines = append(lines, escape(file.Name()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isFolder(file files.File) bool {
+	_, ok := file.(folder)
+	return ok
+}
+
+// leaves returns the files a row stands for: a group's files, every file in a folder,
+// or just the file.
+func leaves(file files.File) files.Files {
+	switch f := file.(type) {
+	case group:
+		return f.children
+	case folder:
+		var out files.Files
+		for _, child := range f.children {
+			out = append(out, leaves(child)...)
+		}
+		return out
+	}
+	return files.Files{file}
+}
+
+// groupDirs puts files, already grouped by version, into a folder for each directory
+// they were trashed from, sorted with sorter by their sizes, latest dates and so on.
+func groupDirs(fls files.Files, sorter func(a, b files.File) int) files.Files {
+	var (
+		out   files.Files
+		order []string
+		byDir = map[string]files.Files{}
+	)
+
+	for _, file := range fls {
+		dir := filepath.Dir(file.Path())
+		if _, ok := byDir[dir]; !ok {
+			order = append(order, dir)
+		}
+		byDir[dir] = append(byDir[dir], file)
+	}
+
+	for _, dir := range order {
+		out = append(out, folder{path: dir, children: byDir[dir]})
+	}
+	slices.SortStableFunc(out, sorter)
+	return out
+}
+
+// inTree checks if the files shown are in a tree, which they aren't inside trashed directories.
+func (m *model) inTree() bool {
+	return m.tree && len(m.levels) == 0
+}
+
+// toggleTree shows the files in a tree, or back in a list, keeping the cursor on the
+// same file, or the folder it's in if that's collapsed.
+func (m *model) toggleTree() {
+	var hovered files.File
+	if cursor := m.table.Cursor(); cursor >= 0 && cursor < len(m.shown) {
+		hovered = m.shown[cursor]
+	}
+
+	m.tree = !m.tree
+	m.applyFilter()
+
+	if hovered != nil {
+		m.moveTo(hovered)
+	}
+}
+
+// moveTo puts the cursor on file, or the row it's in, if it's showing.
+func (m *model) moveTo(file files.File) {
+	var (
+		name     = file.String()
+		dir      = folder{path: filepath.Dir(file.Path())}.String()
+		groupAt  = -1
+		folderAt = -1
+	)
+
+	for i, shown := range m.shown {
+		switch {
+		case shown.String() == name:
+			m.table.SetCursor(i)
+			return
+		case shown.String() == dir:
+			folderAt = i
+		case isGroup(shown) && shown.Path() == file.Path():
+			groupAt = i
+		}
+	}
+
+	switch {
+	case groupAt >= 0:
+		m.table.SetCursor(groupAt)
+	case folderAt >= 0:
+		m.table.SetCursor(folderAt)
+	}
+}
+
+// pluralFiles is count files, in words.
+func pluralFiles(count int) string {
+	if count == 1 {
+		return "1 file"
+	}
+	return fmt.Sprintf("%d files", count)
+}
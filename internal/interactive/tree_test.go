@@ -0,0 +1,69 @@
+package interactive
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"git.burning.moe/celediel/gt/internal/files"
+)
+
+func TestGroupDirs(t *testing.T) {
+	var (
+		then = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		fls  = files.Files{
+			fake{path: "/b/small", size: 1, date: then},
+			fake{path: "/a/big", size: 100, date: then.Add(-time.Hour)},
+			fake{path: "/b/medium", size: 10, date: then.Add(-2 * time.Hour)},
+			fake{path: "/c/newest", size: 5, date: then.Add(time.Hour)},
+		}
+	)
+
+	tests := []struct {
+		name   string
+		sorter func(a, b files.File) int
+		want   []string
+	}{
+		{"path", files.SortByPath, []string{"/a", "/b", "/c"}},
+		{"path reversed", files.SortByPathReverse, []string{"/c", "/b", "/a"}},
+		{"size", files.SortBySize, []string{"/c", "/b", "/a"}},
+		{"size reversed", files.SortBySizeReverse, []string{"/a", "/b", "/c"}},
+		{"newest first", files.SortByModified, []string{"/c", "/b", "/a"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := paths(groupDirs(fls, test.sorter)); !slices.Equal(got, test.want) {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestLeaves(t *testing.T) {
+	var (
+		a = fake{path: "/d/a"}
+		b = fake{path: "/d/b", date: time.Unix(1, 0)}
+		c = fake{path: "/d/c"}
+		g = group{name: "b", path: "/d/b", children: files.Files{b, b}}
+	)
+
+	tests := []struct {
+		name string
+		file files.File
+		want int
+	}{
+		{"file", a, 1},
+		{"group", g, 2},
+		{"folder", folder{path: "/d", children: files.Files{a, g, c}}, 4},
+		{"empty folder", folder{path: "/d"}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := len(leaves(test.file)); got != test.want {
+				t.Fatalf("expected %d files, got %d", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Package lscolors colors file names the way ls does, from the LS_COLORS environment variable.
+package lscolors
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git.burning.moe/celediel/gt/internal/files"
+)
+
+const (
+	envVar string = "LS_COLORS"
+	reset  string = "\x1b[0m"
+)
+
+// Colors are the escape codes file names are colored with, by file type, like di for
+// directories, and by name, like *.tar.
+type Colors struct {
+	types map[string]string
+	// suffixes are in the order they're set, and the last that matches is used, like ls
+	suffixes [][2]string
+}
+
+// Load reads colors from LS_COLORS, or none if it isn't set.
+func Load() Colors {
+	return Parse(os.Getenv(envVar))
+}
+
+// Parse reads colors from value, in the form LS_COLORS is, like di=01;34:*.tar=01;31
+func Parse(value string) Colors {
+	c := Colors{types: map[string]string{}}
+
+	for _, entry := range strings.Split(value, ":") {
+		key, code, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(key, "*"); ok {
+			c.suffixes = append(c.suffixes, [2]string{strings.ToLower(suffix), code})
+		} else {
+			c.types[key] = code
+		}
+	}
+
+	return c
+}
+
+// Empty checks if there are no colors to use.
+func (c Colors) Empty() bool {
+	return len(c.types) == 0 && len(c.suffixes) == 0
+}
+
+// Paint colors name as file is colored by ls.
+func (c Colors) Paint(file files.File, name string) string {
+	code := c.Code(file)
+	if code == "" || code == "0" || code == "00" {
+		return name
+	}
+	return "\x1b[" + code + "m" + name + reset
+}
+
+// Code returns the escape code for file, by its type, then its name for regular files.
+func (c Colors) Code(file files.File) string {
+	if c.Empty() {
+		return ""
+	}
+
+	mode := file.Mode()
+	switch {
+	case file.IsDir() || mode.IsDir():
+		return c.dirCode(mode)
+	case mode&fs.ModeSymlink != 0:
+		if c.types["ln"] == "target" {
+			return c.targetCode(file)
+		}
+		if broken(file) {
+			return c.first("or", "ln")
+		}
+		return c.types["ln"]
+	case mode&fs.ModeNamedPipe != 0:
+		return c.types["pi"]
+	case mode&fs.ModeSocket != 0:
+		return c.types["so"]
+	case mode&fs.ModeCharDevice != 0:
+		return c.types["cd"]
+	case mode&fs.ModeDevice != 0:
+		return c.types["bd"]
+	}
+
+	switch {
+	case mode&fs.ModeSetuid != 0 && c.types["su"] != "":
+		return c.types["su"]
+	case mode&fs.ModeSetgid != 0 && c.types["sg"] != "":
+		return c.types["sg"]
+	case mode&0o111 != 0 && c.types["ex"] != "":
+		return c.types["ex"]
+	}
+
+	if code, ok := c.suffixCode(file.Name()); ok {
+		return code
+	}
+	return c.first("fi", "no")
+}
+
+func (c Colors) dirCode(mode fs.FileMode) string {
+	sticky, otherWritable := mode&fs.ModeSticky != 0, mode&0o002 != 0
+	switch {
+	case sticky && otherWritable && c.types["tw"] != "":
+		return c.types["tw"]
+	case otherWritable && c.types["ow"] != "":
+		return c.types["ow"]
+	case sticky && c.types["st"] != "":
+		return c.types["st"]
+	default:
+		return c.types["di"]
+	}
+}
+
+// targetCode is the code for what the symlink file points to, for ln=target.
+func (c Colors) targetCode(file files.File) string {
+	info, err := os.Stat(target(file))
+	if err != nil {
+		return c.first("or", "fi")
+	}
+	if info.IsDir() {
+		return c.dirCode(info.Mode())
+	}
+	if code, ok := c.suffixCode(file.Name()); ok {
+		return code
+	}
+	return c.first("fi", "no")
+}
+
+// suffixCode is the code for the last suffix name ends with, ignoring case.
+func (c Colors) suffixCode(name string) (string, bool) {
+	name = strings.ToLower(name)
+	for i := len(c.suffixes) - 1; i >= 0; i-- {
+		if strings.HasSuffix(name, c.suffixes[i][0]) {
+			return c.suffixes[i][1], true
+		}
+	}
+	return "", false
+}
+
+// first returns the code of the first type set.
+func (c Colors) first(types ...string) string {
+	for _, t := range types {
+		if code := c.types[t]; code != "" {
+			return code
+		}
+	}
+	return ""
+}
+
+// broken checks if the symlink file points to something that doesn't exist.
+func broken(file files.File) bool {
+	_, err := os.Stat(target(file))
+	return err != nil
+}
+
+// target is where the symlink file points, from where it was trashed from, since
+// relative links in the trash point somewhere else.
+func target(file files.File) string {
+	link, err := os.Readlink(files.Location(file))
+	if err != nil {
+		return files.Location(file)
+	}
+	if !filepath.IsAbs(link) {
+		link = filepath.Join(filepath.Dir(file.Path()), link)
+	}
+	return link
+}
@@ -0,0 +1,63 @@
+package lscolors_test
+
+import (
+	"io/fs"
+	"testing"
+	"time"
+
+	"git.burning.moe/celediel/gt/internal/lscolors"
+)
+
+type testfile struct {
+	name string
+	mode fs.FileMode
+}
+
+func (f testfile) Name() string      { return f.name }
+func (f testfile) Path() string      { return "/nowhere/" + f.name }
+func (f testfile) Date() time.Time   { return time.Time{} }
+func (f testfile) Filesize() int64   { return 0 }
+func (f testfile) IsDir() bool       { return f.mode.IsDir() }
+func (f testfile) Mode() fs.FileMode { return f.mode }
+func (f testfile) String() string    { return f.Path() }
+
+func TestCode(t *testing.T) {
+	colors := lscolors.Parse("rs=0:di=01;34:ow=34;42:ex=01;32:fi=00:*.tar=01;31:*.TAR.GZ=01;35:*README=04")
+
+	tests := []struct {
+		file testfile
+		want string
+	}{
+		{testfile{"dir", fs.ModeDir | 0o755}, "01;34"},
+		{testfile{"shared", fs.ModeDir | 0o777}, "34;42"},
+		{testfile{"run.sh", 0o755}, "01;32"},
+		{testfile{"backup.tar", 0o644}, "01;31"},
+		{testfile{"backup.tar.gz", 0o644}, "01;35"},
+		{testfile{"BACKUP.TAR", 0o644}, "01;31"},
+		{testfile{"README", 0o644}, "04"},
+		{testfile{"notes.txt", 0o644}, "00"},
+		{testfile{"fifo", fs.ModeNamedPipe | 0o644}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file.name, func(t *testing.T) {
+			if got := colors.Code(test.file); got != test.want {
+				t.Fatalf("expected '%s', got '%s'", test.want, got)
+			}
+		})
+	}
+}
+
+func TestPaint(t *testing.T) {
+	colors := lscolors.Parse("di=01;34:fi=00")
+
+	if got := colors.Paint(testfile{"dir", fs.ModeDir}, "dir/"); got != "\x1b[01;34mdir/\x1b[0m" {
+		t.Fatalf("expected a colored directory, got %q", got)
+	}
+	if got := colors.Paint(testfile{"file", 0o644}, "file"); got != "file" {
+		t.Fatalf("expected fi=00 to leave the name alone, got %q", got)
+	}
+	if got := lscolors.Parse("").Paint(testfile{"dir", fs.ModeDir}, "dir"); got != "dir" {
+		t.Fatalf("expected no colors to leave the name alone, got %q", got)
+	}
+}
@@ -3,60 +3,170 @@ package prompt
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
 
 	"github.com/charmbracelet/huh"
 	"golang.org/x/term"
 )
 
-func YesNo(prompt string) bool {
-	return AskRune(prompt, "y/n") == 'y'
+const ttyPath string = "/dev/tty"
+
+// ErrNoTTY is returned when there's nothing to ask and no answer to assume.
+var ErrNoTTY = errors.New("no terminal to prompt on (try --yes or --assume-no)")
+
+var (
+	assumed byte // answer given to every prompt without asking, if not 0
+	noTTY   byte // answer given when there's no terminal to ask, if not 0
+)
+
+// Assume answers every prompt with yes or no without asking.
+func Assume(yes bool) {
+	assumed = yesno(yes)
 }
 
-func AskRune(prompt, options string) byte {
-	// switch stdin into 'raw' mode
-	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
-	if err != nil {
-		log.Fatal(err)
+// Assumed returns the answer every prompt is given without asking, if there is one.
+func Assumed() (yes, ok bool) {
+	return assumed == 'y', assumed != 0
+}
+
+// SetNoTTYPolicy sets what prompts answer when neither stdin
+// nor /dev/tty is a terminal: "fail", "yes" or "no".
+func SetNoTTYPolicy(policy string) error {
+	switch policy {
+	case "fail", "":
+		noTTY = 0
+	case "yes":
+		noTTY = 'y'
+	case "no":
+		noTTY = 'n'
+	default:
+		return fmt.Errorf("unknown no-tty policy '%s' (possible values: fail, yes, no)", policy)
 	}
-	defer func() {
-		if err := term.Restore(int(os.Stdin.Fd()), oldState); err != nil {
-			log.Fatal(err)
+	return nil
+}
+
+func YesNo(prompt string) (bool, error) {
+	answer, err := AskRune(prompt, "y/n")
+	return answer == 'y', err
+}
+
+func AskRune(prompt, options string) (byte, error) {
+	return askRune(prompt, options, 0)
+}
+
+// askRune asks prompt, unless an answer is assumed, when it answers with instead if
+// that's set, for prompts where yes or no doesn't mean anything, or the assumed answer.
+func askRune(prompt, options string, instead byte) (byte, error) {
+	answer := func(assumed byte) (byte, error) {
+		if instead != 0 {
+			assumed = instead
+		}
+		fmt.Fprintf(os.Stdout, "%s [%s]: %c\n", prompt, options, assumed)
+		return assumed, nil
+	}
+
+	if assumed != 0 {
+		return answer(assumed)
+	}
+
+	tty, closer, err := openTTY()
+	if err != nil {
+		if noTTY != 0 {
+			return answer(noTTY)
 		}
-	}()
+		return 0, err
+	}
+	defer closer()
 
-	fmt.Fprintf(os.Stdout, "%s [%s]: ", prompt, options)
+	// switch the terminal into 'raw' mode
+	oldState, err := term.MakeRaw(int(tty.Fd()))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = term.Restore(int(tty.Fd()), oldState) }()
 
-	// read one byte from stdin
+	fmt.Fprintf(output(tty), "%s [%s]: ", prompt, options)
+
+	// read one byte from the terminal
 	one := make([]byte, 1)
-	_, err = os.Stdin.Read(one)
+	_, err = tty.Read(one)
 	if err != nil {
-		return 0
+		return 0, nil
 	}
 
-	return bytes.ToLower(one)[0]
+	return bytes.ToLower(one)[0], nil
 }
 
-func NewPath(path string) (string, bool) {
+// NewPath asks what to do about path already existing, and returns the path
+// to use, and whether or not the user chose to cancel. An assumed answer cancels,
+// since yes or no doesn't say whether to overwrite or rename.
+func NewPath(path string) (string, bool, error) {
 	for {
-		answer := AskRune(fmt.Sprintf("file %s exists, overwrite, rename, or cancel?", path), "o/r/c")
+		answer, err := askRune(fmt.Sprintf("file %s exists, overwrite, rename, or cancel?", path), "o/r/c", 'c')
+		if err != nil {
+			return path, true, err
+		}
 		switch answer {
 		case 'o', 'O':
-			return path, false
+			return path, false, nil
 		case 'r', 'R':
-			if err := huh.NewInput().
-				Title("input a new filename").
-				Value(&path).
-				Run(); err != nil {
-				return path, false
+			tty, closer, err := openTTY()
+			if err != nil {
+				return path, true, err
+			}
+			err = huh.NewForm(huh.NewGroup(
+				huh.NewInput().
+					Title("input a new filename").
+					Value(&path),
+			)).
+				WithInput(tty).
+				WithOutput(output(tty)).
+				Run()
+			closer()
+			if err != nil {
+				return path, false, nil
 			}
 			if _, e := os.Lstat(path); e != nil {
-				return path, false
+				return path, false, nil
 			}
 		default:
-			return path, true
+			return path, true, nil
 		}
 	}
 }
+
+// openTTY returns stdin if it's a terminal, or otherwise /dev/tty,
+// so prompts still work when stdin is a pipe.
+func openTTY() (*os.File, func(), error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return os.Stdin, func() {}, nil
+	}
+
+	tty, err := os.OpenFile(ttyPath, os.O_RDWR, 0)
+	if err != nil || !term.IsTerminal(int(tty.Fd())) {
+		if tty != nil {
+			tty.Close()
+		}
+		return nil, nil, ErrNoTTY
+	}
+
+	return tty, func() { tty.Close() }, nil
+}
+
+// output writes prompts to the terminal being read from, unless that's stdin.
+func output(tty *os.File) io.Writer {
+	if tty == os.Stdin {
+		return os.Stdout
+	}
+	return tty
+}
+
+func yesno(yes bool) byte {
+	if yes {
+		return 'y'
+	}
+	return 'n'
+}
@@ -0,0 +1,36 @@
+package prompt
+
+import "testing"
+
+func TestNewPathAssumed(t *testing.T) {
+	defer func() { assumed, noTTY = 0, 0 }()
+
+	tests := []struct {
+		name           string
+		assumed, noTTY byte
+	}{
+		{"yes", 'y', 0},
+		{"no", 'n', 0},
+		{"no-tty yes", 0, 'y'},
+		{"no-tty no", 0, 'n'},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.assumed == 0 {
+				if _, closer, err := openTTY(); err == nil {
+					closer()
+					t.Skip("there's a terminal to ask on")
+				}
+			}
+			assumed, noTTY = test.assumed, test.noTTY
+			path, cancel, err := NewPath("/nowhere/file")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cancel {
+				t.Fatalf("expected an assumed answer to cancel, got to keep going with %s", path)
+			}
+		})
+	}
+}
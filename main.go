@@ -7,18 +7,26 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"slices"
+	"strings"
 	"time"
 
+	"git.burning.moe/celediel/gt/internal/config"
 	"git.burning.moe/celediel/gt/internal/filemode"
 	"git.burning.moe/celediel/gt/internal/files"
 	"git.burning.moe/celediel/gt/internal/filter"
 	"git.burning.moe/celediel/gt/internal/interactive"
 	"git.burning.moe/celediel/gt/internal/interactive/modes"
+	"git.burning.moe/celediel/gt/internal/lscolors"
+	"git.burning.moe/celediel/gt/internal/prompt"
 	"golang.org/x/term"
 
 	"github.com/adrg/xdg"
 	"github.com/charmbracelet/log"
+	"github.com/dustin/go-humanize"
+	"github.com/ijt/go-anytime"
 	"github.com/urfave/cli/v2"
 )
 
@@ -37,6 +45,7 @@ See gt(1) for more information.`
 
 var (
 	fltr                       *filter.Filter
+	cfg                        *config.Config
 	loglvl                     string
 	onArg, beforeArg, afterArg string
 	globArg, patternArg        string
@@ -48,8 +57,32 @@ var (
 	workdir, ogdir             cli.Path
 	recursive                  bool
 	isTerminal                 bool
+	print0Arg, stdinArg        bool
+	nullArg                    bool
+	filesFromArg               cli.Path
+	yesArg, noArg              bool
+	noTTYArg                   string
+	colorArg                   string
+	noMouseArg                 bool
+	nameColors                 lscolors.Colors
+	onConflictArg, renameArg   string
+	mergeConflictArg           string
+	restoreTo                  cli.Path
+	keepStructure              bool
+	asOfArg                    string
+	versionsArg                bool
+	versionArg                 string
+	idArgs                     []string
+	noteArg, noteMatchArg      string
+	clearNoteArg, deepArg      bool
+	ignoreCaseArg, binaryArg   bool
+	selectArg                  bool
+	sizeLimitArg               string
+	workersArg                 int
+	tagArgs, tagFilterArgs     cli.StringSlice
+	addTagArgs, removeTagArgs  cli.StringSlice
 
-	beforeAll = func(_ *cli.Context) error {
+	beforeAll = func(ctx *cli.Context) error {
 		if term.IsTerminal(int(os.Stdout.Fd())) && term.IsTerminal(int(os.Stdin.Fd())) {
 			isTerminal = true
 		}
@@ -71,6 +104,51 @@ var (
 			log.SetLevel(math.MaxInt32)
 		}
 
+		// load config, then let flags override it
+		var err error
+		if cfg, err = config.Load(); err != nil {
+			return fmt.Errorf("couldn't read config file %s: %w", config.Path, err)
+		}
+
+		// setup prompts
+		if yesArg && noArg {
+			return fmt.Errorf("--yes and --assume-no can't be used together")
+		} else if yesArg || noArg {
+			prompt.Assume(yesArg)
+		}
+		if !ctx.IsSet("no-tty") {
+			noTTYArg = cfg.Prompt.NoTTY
+		}
+		if err := prompt.SetNoTTYPolicy(noTTYArg); err != nil {
+			return err
+		}
+		if err := interactive.SetPreview(cfg.Preview.Show, cfg.Preview.Position, cfg.Preview.Command); err != nil {
+			return err
+		}
+		if err := interactive.SetKeys(cfg.Keys.Preset, cfg.Keys.Bindings); err != nil {
+			return fmt.Errorf("bad keys in config file %s: %w", config.Path, err)
+		}
+		if err := interactive.SetTheme(cfg.Theme.Name, cfg.Theme.Background, cfg.Theme.Styles); err != nil {
+			return fmt.Errorf("bad theme in config file %s: %w", config.Path, err)
+		}
+		if err := interactive.SetColumns(cfg.Table.Columns); err != nil {
+			return fmt.Errorf("bad table in config file %s: %w", config.Path, err)
+		}
+		interactive.SetTree(cfg.Table.Tree)
+		interactive.SetMouse(cfg.Table.Mouse && !noMouseArg)
+		switch colorArg {
+		case "always":
+			nameColors = lscolors.Load()
+		case "auto":
+			if term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("NO_COLOR") == "" {
+				nameColors = lscolors.Load()
+			}
+		case "never":
+		default:
+			return fmt.Errorf("unknown color setting '%s' (possible values: auto, always, never)", colorArg)
+		}
+		interactive.SetNameColors(nameColors)
+
 		// ensure personal trash directories exist
 		homeTrash := filepath.Join(xdg.DataHome, "Trash")
 		if _, e := os.Lstat(filepath.Join(homeTrash, "info")); os.IsNotExist(e) {
@@ -106,14 +184,7 @@ var (
 
 		if len(ctx.Args().Slice()) == 0 {
 			// no ags, so do interactive mode
-			var (
-				infiles  files.Files
-				selected files.Files
-				mode     modes.Mode
-				err      error
-			)
-
-			infiles = files.FindInAllTrashes(ogdir, fltr)
+			infiles := files.FindInAllTrashes(ogdir, fltr)
 			if len(infiles) <= 0 {
 				var msg string
 				if fltr.Blank() {
@@ -129,31 +200,24 @@ var (
 				fmt.Fprint(os.Stdout, infiles.String())
 				return nil
 			}
-			selected, mode, err = interactive.Select(infiles, false, false, workdir, modes.Interactive)
+
+			restoreOpts, err := restoreOptions()
 			if err != nil {
 				return err
 			}
-			switch mode {
-			case modes.Cleaning:
-				for _, file := range selected {
-					log.Debugf("gonna clean %s", file.Name())
-				}
-				if err := files.ConfirmClean(askconfirm, selected); err != nil {
-					return err
-				}
-			case modes.Restoring:
-				for _, file := range selected {
-					log.Debugf("gonna restore %s", file.Name())
-				}
-				if err := files.ConfirmRestore(askconfirm, selected); err != nil {
-					return err
-				}
-			case modes.Interactive:
-				return nil
-			default:
-				return fmt.Errorf("got bad mode %s", mode)
+			restoreHereOpts, err := restoreHereOptions()
+			if err != nil {
+				return err
 			}
-			return nil
+
+			return interactive.Manage(infiles, workdir, interactive.Actions{
+				Find: func() files.Files {
+					return files.FindInAllTrashes(ogdir, fltr)
+				},
+				Restore:     restoreOpts,
+				RestoreHere: restoreHereOpts,
+				Confirm:     askconfirm,
+			})
 		}
 
 		// args, so try to trash files
@@ -166,20 +230,19 @@ var (
 			}
 			filesToTrash = append(filesToTrash, file)
 		}
-		return files.ConfirmTrash(askconfirm, filesToTrash)
+		return files.ConfirmTrash(askconfirm, filesToTrash, files.TrashOptions{})
 	}
 
-	beforeCommands = func(ctx *cli.Context) (err error) {
-		// setup filter
-		if fltr == nil {
-			md, e := filemode.Parse(modeArg)
-			if e != nil {
-				return e
-			}
-			fltr, err = filter.New(onArg, beforeArg, afterArg, globArg, patternArg, unGlobArg, unPatternArg, filesOnlyArg, dirsOnlyArg, false, minArg, maxArg, md, ctx.Args().Slice()...)
+	beforeCommands = func(ctx *cli.Context) error {
+		return setupTrashedFilter(ctx.Args().Slice())
+	}
+
+	beforeGrep = func(ctx *cli.Context) error {
+		if !ctx.Args().Present() {
+			return fmt.Errorf("no pattern to search for")
 		}
-		log.Debugf("filter: %s", fltr.String())
-		return
+		// the rest are names or @ids like any other command
+		return setupTrashedFilter(ctx.Args().Tail())
 	}
 
 	beforeTrash = func(_ *cli.Context) (err error) {
@@ -208,6 +271,28 @@ var (
 		Before:    beforeTrash,
 		Action: func(ctx *cli.Context) error {
 			var filesToTrash files.Files
+
+			// paths from a pipe or file skip the table entirely
+			if stdinArg || filesFromArg != "" {
+				paths, err := inputPaths()
+				if err != nil {
+					return err
+				}
+				for _, path := range append(paths, ctx.Args().Slice()...) {
+					file, e := files.NewDisk(path)
+					if e != nil {
+						log.Errorf("cannot trash '%s': No such file or directory", path)
+						continue
+					}
+					filesToTrash = append(filesToTrash, file)
+				}
+				if len(filesToTrash) == 0 {
+					fmt.Fprintln(os.Stdout, "no files to trash")
+					return nil
+				}
+				return files.ConfirmTrash(askconfirm, filesToTrash, trashOptions())
+			}
+
 			for _, arg := range ctx.Args().Slice() {
 				file, e := files.NewDisk(arg)
 				if e != nil || workdir != "" {
@@ -237,7 +322,7 @@ var (
 				return nil
 			}
 
-			return files.ConfirmTrash(askconfirm, selected)
+			return files.ConfirmTrash(askconfirm, selected, trashOptions())
 		},
 	}
 
@@ -248,7 +333,10 @@ var (
 		Flags:   slices.Concat(listFlags, trashedFlags, filterFlags),
 		Before:  beforeCommands,
 		Action: func(_ *cli.Context) error {
-			fls := files.FindInAllTrashes(ogdir, fltr)
+			fls, err := findTrashed()
+			if err != nil {
+				return err
+			}
 
 			var msg string
 			log.Debugf("filter '%s' is blank? %t in %s", fltr, fltr.Blank(), ogdir)
@@ -258,13 +346,25 @@ var (
 				msg = "no files to show"
 			}
 
+			if print0Arg {
+				fmt.Fprint(os.Stdout, fls.Paths("\x00"))
+				return nil
+			}
+
+			if versionsArg && len(fls) > 0 {
+				fmt.Fprint(os.Stdout, files.VersionsString(fls))
+				return nil
+			}
+
 			if len(fls) == 0 {
 				fmt.Fprintln(os.Stdout, msg)
 				return nil
 			}
 
 			if !isTerminal {
-				fmt.Fprint(os.Stdout, fls.String())
+				fmt.Fprint(os.Stdout, fls.Format("\n", func(file files.File) string {
+					return nameColors.Paint(file, file.Name())
+				}))
 				return nil
 			}
 
@@ -277,25 +377,47 @@ var (
 		Aliases:   []string{"re"},
 		Usage:     "Restore a trashed file or files",
 		UsageText: "[command options] [filename(s)]",
-		Flags:     slices.Concat(cleanRestoreFlags, trashedFlags, filterFlags),
+		Flags:     slices.Concat(cleanRestoreFlags, restoreFlags, trashedFlags, filterFlags),
 		Before:    beforeCommands,
-		Action: func(_ *cli.Context) error {
-			fls := files.FindInAllTrashes(ogdir, fltr)
+		Action: func(ctx *cli.Context) error {
+			opts, err := restoreOptions()
+			if err != nil {
+				return err
+			}
+
+			if asOfArg != "" {
+				return restoreAsOf(opts)
+			}
+
+			if versionArg != "" {
+				return restoreVersions(ctx.Args().Slice(), opts)
+			}
+
+			fls, err := findTrashed()
+			if err != nil {
+				return err
+			}
 			if len(fls) == 0 {
 				fmt.Fprintln(os.Stdout, "no files to restore")
 				return nil
 			}
 
-			selected, _, err := interactive.Select(fls, all, all, workdir, modes.Restoring)
+			selected, mode, err := selectTrashed(fls, modes.Restoring)
 			if err != nil {
 				return err
 			}
 
+			if mode == modes.RestoringHere {
+				if opts, err = restoreHereOptions(); err != nil {
+					return err
+				}
+			}
+
 			if len(selected) <= 0 {
 				return nil
 			}
 
-			return files.ConfirmRestore(askconfirm || all, selected)
+			return files.ConfirmRestore(askconfirm || all, selected, opts)
 		},
 	}
 
@@ -307,13 +429,16 @@ var (
 		Flags:     slices.Concat(cleanRestoreFlags, trashedFlags, filterFlags),
 		Before:    beforeCommands,
 		Action: func(_ *cli.Context) error {
-			fls := files.FindInAllTrashes(ogdir, fltr)
+			fls, err := findTrashed()
+			if err != nil {
+				return err
+			}
 			if len(fls) == 0 {
 				fmt.Fprintln(os.Stdout, "no files to clean")
 				return nil
 			}
 
-			selected, _, err := interactive.Select(fls, all, all, workdir, modes.Cleaning)
+			selected, _, err := selectTrashed(fls, modes.Cleaning)
 			if err != nil {
 				return err
 			}
@@ -326,6 +451,176 @@ var (
 		},
 	}
 
+	doTag = &cli.Command{
+		Name:      "tag",
+		Usage:     "Change the note and tags of trashed files",
+		UsageText: "[command options] [filename(s)]",
+		Flags:     slices.Concat(cleanRestoreFlags, tagFlags, trashedFlags, filterFlags),
+		Before:    beforeCommands,
+		Action: func(_ *cli.Context) error {
+			fls, err := findTrashed()
+			if err != nil {
+				return err
+			}
+			if len(fls) == 0 {
+				fmt.Fprintln(os.Stdout, "no files to tag")
+				return nil
+			}
+
+			selected, _, err := selectTrashed(fls, modes.Tagging)
+			if err != nil {
+				return err
+			}
+
+			if len(selected) <= 0 {
+				return nil
+			}
+
+			return files.ConfirmTag(askconfirm, selected, files.TagOptions{
+				Add:       addTagArgs.Value(),
+				Remove:    removeTagArgs.Value(),
+				Note:      noteArg,
+				ClearNote: clearNoteArg,
+			})
+		},
+	}
+
+	doGrep = &cli.Command{
+		Name:      "grep",
+		Usage:     "Search the contents of trashed files",
+		UsageText: "[command options] pattern [filename(s)]",
+		Flags:     slices.Concat(grepFlags, trashedFlags, filterFlags),
+		Before:    beforeGrep,
+		Action: func(ctx *cli.Context) error {
+			pattern := ctx.Args().First()
+			if ignoreCaseArg {
+				pattern = "(?i)" + pattern
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return err
+			}
+
+			var limit uint64
+			if sizeLimitArg != "" {
+				if limit, err = humanize.ParseBytes(sizeLimitArg); err != nil {
+					return fmt.Errorf("invalid size limit '%s': %w", sizeLimitArg, err)
+				}
+			}
+
+			fls, err := findTrashed()
+			if err != nil {
+				return err
+			}
+
+			results := files.Grep(fls, files.GrepOptions{
+				Pattern: re,
+				MaxSize: int64(limit),
+				Binary:  binaryArg,
+				Workers: workersArg,
+			})
+			if len(results) == 0 {
+				fmt.Fprintln(os.Stdout, "no files matched")
+				return nil
+			}
+
+			// files that couldn't all be searched still show what was found in them,
+			// but make grep fail
+			var (
+				matched files.Files
+				failed  int
+			)
+			for _, result := range results {
+				if result.Err != nil {
+					fmt.Fprintf(os.Stderr, "error searching '%s': %s\n", result.File.Path(), result.Err)
+					failed++
+				}
+				if len(result.Matches) == 0 {
+					continue
+				}
+				matched = append(matched, result.File)
+				if !selectArg {
+					fmt.Fprint(os.Stdout, result)
+				}
+			}
+
+			var searchErr error
+			if failed > 0 {
+				searchErr = fmt.Errorf("couldn't finish searching %d files", failed)
+			}
+			if !selectArg || len(matched) == 0 {
+				return searchErr
+			}
+
+			opts, err := restoreOptions()
+			if err != nil {
+				return err
+			}
+
+			selected, mode, err := interactive.Select(matched, false, false, workdir, modes.Restoring)
+			if err != nil {
+				return err
+			}
+
+			if mode == modes.RestoringHere {
+				if opts, err = restoreHereOptions(); err != nil {
+					return err
+				}
+			}
+
+			if len(selected) <= 0 {
+				return searchErr
+			}
+
+			if err := files.ConfirmRestore(askconfirm, selected, opts); err != nil {
+				return err
+			}
+			return searchErr
+		},
+	}
+
+	doCat = &cli.Command{
+		Name:      "cat",
+		Usage:     "Print the contents of trashed files",
+		UsageText: "[command options] filename(s)",
+		Flags:     slices.Concat(trashedFlags, filterFlags),
+		Before:    beforeCommands,
+		Action: func(ctx *cli.Context) error {
+			fls, err := findGiven(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, file := range fls {
+				if err := files.Cat(file, os.Stdout); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	doDiff = &cli.Command{
+		Name:      "diff",
+		Usage:     "Show how trashed files differ from what's where they were trashed from now",
+		UsageText: "[command options] filename(s)",
+		Flags:     slices.Concat(trashedFlags, filterFlags),
+		Before:    beforeCommands,
+		Action: func(ctx *cli.Context) error {
+			fls, err := findGiven(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, file := range fls {
+				if err := files.Diff(file, os.Stdout); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
 	globalFlags = []cli.Flag{
 		&cli.StringFlag{
 			Name:        "log",
@@ -342,6 +637,37 @@ var (
 			DisableDefaultText: true,
 			Destination:        &askconfirm,
 		},
+		&cli.BoolFlag{
+			Name:               "yes",
+			Usage:              "answer yes to every question",
+			Aliases:            []string{"y"},
+			DisableDefaultText: true,
+			Destination:        &yesArg,
+		},
+		&cli.BoolFlag{
+			Name:               "assume-no",
+			Usage:              "answer no to every question",
+			DisableDefaultText: true,
+			Destination:        &noArg,
+		},
+		&cli.StringFlag{
+			Name:        "no-tty",
+			Usage:       "answer questions with `POLICY` (fail, yes, no) when there's no terminal to ask",
+			EnvVars:     []string{"GT_NO_TTY"},
+			Destination: &noTTYArg,
+		},
+		&cli.StringFlag{
+			Name:        "color",
+			Usage:       "color file names with LS_COLORS `WHEN` (auto, always, never)",
+			Value:       "auto",
+			Destination: &colorArg,
+		},
+		&cli.BoolFlag{
+			Name:               "no-mouse",
+			Usage:              "don't use the mouse in the interactive table",
+			DisableDefaultText: true,
+			Destination:        &noMouseArg,
+		},
 	}
 
 	filterFlags = []cli.Flag{
@@ -443,6 +769,35 @@ var (
 			DisableDefaultText: true,
 			Destination:        &hiddenArg,
 		},
+		&cli.BoolFlag{
+			Name:               "stdin",
+			Usage:              "read paths to trash from standard input",
+			DisableDefaultText: true,
+			Destination:        &stdinArg,
+		},
+		&cli.PathFlag{
+			Name:        "files-from",
+			Usage:       "read paths to trash from `FILE`",
+			Aliases:     []string{"T"},
+			Destination: &filesFromArg,
+		},
+		&cli.BoolFlag{
+			Name:               "null",
+			Usage:              "paths read from input are separated by NUL instead of newlines",
+			Aliases:            []string{"0"},
+			DisableDefaultText: true,
+			Destination:        &nullArg,
+		},
+		&cli.StringFlag{
+			Name:        "note",
+			Usage:       "record `TEXT` as a note on why files were trashed",
+			Destination: &noteArg,
+		},
+		&cli.StringSliceFlag{
+			Name:        "tag",
+			Usage:       "tag trashed files with `TAG`; may be given more than once",
+			Destination: &tagArgs,
+		},
 	}
 
 	trashedFlags = []cli.Flag{
@@ -452,6 +807,75 @@ var (
 			Aliases:     []string{"o"},
 			Destination: &ogdir,
 		},
+		&cli.StringSliceFlag{
+			Name:        "tag",
+			Usage:       "operate on files trashed with `TAG`; may be given more than once to require several",
+			Destination: &tagFilterArgs,
+		},
+		&cli.StringFlag{
+			Name:        "note-match",
+			Usage:       "operate on files with notes matching `REGEX`",
+			Destination: &noteMatchArg,
+		},
+	}
+
+	grepFlags = []cli.Flag{
+		&cli.BoolFlag{
+			Name:               "ignore-case",
+			Usage:              "match the pattern regardless of case",
+			Aliases:            []string{"i"},
+			DisableDefaultText: true,
+			Destination:        &ignoreCaseArg,
+		},
+		&cli.BoolFlag{
+			Name:               "binary",
+			Usage:              "search binary files too",
+			DisableDefaultText: true,
+			Destination:        &binaryArg,
+		},
+		&cli.StringFlag{
+			Name:        "size-limit",
+			Usage:       "skip files larger than `SIZE`, or none if empty",
+			Value:       "10M",
+			Destination: &sizeLimitArg,
+		},
+		&cli.IntFlag{
+			Name:        "workers",
+			Usage:       "search `N` files at a time",
+			Aliases:     []string{"j"},
+			Value:       runtime.NumCPU(),
+			Destination: &workersArg,
+		},
+		&cli.BoolFlag{
+			Name:               "select",
+			Usage:              "show files with matches in the table to select some to restore",
+			DisableDefaultText: true,
+			Destination:        &selectArg,
+		},
+	}
+
+	tagFlags = []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:        "add",
+			Usage:       "add `TAG` to files; may be given more than once",
+			Destination: &addTagArgs,
+		},
+		&cli.StringSliceFlag{
+			Name:        "remove",
+			Usage:       "remove `TAG` from files; may be given more than once",
+			Destination: &removeTagArgs,
+		},
+		&cli.StringFlag{
+			Name:        "note",
+			Usage:       "replace files' notes with `TEXT`",
+			Destination: &noteArg,
+		},
+		&cli.BoolFlag{
+			Name:               "clear-note",
+			Usage:              "remove files' notes",
+			DisableDefaultText: true,
+			Destination:        &clearNoteArg,
+		},
 	}
 
 	listFlags = []cli.Flag{
@@ -462,6 +886,73 @@ var (
 			Destination:        &noInterArg,
 			DisableDefaultText: true,
 		},
+		&cli.BoolFlag{
+			Name:               "versions",
+			Usage:              "list each path files were trashed from, and every version trashed from it, and quit",
+			Aliases:            []string{"V"},
+			Destination:        &versionsArg,
+			DisableDefaultText: true,
+		},
+		&cli.BoolFlag{
+			Name:               "deep",
+			Usage:              "also list files inside trashed directories, and quit",
+			Destination:        &deepArg,
+			DisableDefaultText: true,
+		},
+		&cli.BoolFlag{
+			Name:               "print0",
+			Usage:              "list only the paths files were trashed from, each ended by NUL, and quit",
+			Destination:        &print0Arg,
+			DisableDefaultText: true,
+		},
+	}
+
+	restoreFlags = []cli.Flag{
+		&cli.BoolFlag{
+			Name:               "deep",
+			Usage:              "also restore files inside trashed directories, leaving the rest of the directories in the trash",
+			Destination:        &deepArg,
+			DisableDefaultText: true,
+		},
+		&cli.StringFlag{
+			Name:        "on-conflict",
+			Usage:       "when a file already exists, `POLICY` (skip, overwrite, rename, backup, merge, ask)",
+			Aliases:     []string{"C"},
+			Destination: &onConflictArg,
+		},
+		&cli.StringFlag{
+			Name:        "version",
+			Usage:       "restore `VERSION` (a number, latest, or oldest) of each path given as an argument",
+			Destination: &versionArg,
+		},
+		&cli.StringFlag{
+			Name:        "as-of",
+			Usage:       "restore everything trashed from under --original-path (or here) after `DATE`, as it was then",
+			Destination: &asOfArg,
+		},
+		&cli.PathFlag{
+			Name:        "to",
+			Usage:       "restore files into `DIRECTORY` instead of where they were trashed from",
+			Aliases:     []string{"t"},
+			Destination: &restoreTo,
+		},
+		&cli.BoolFlag{
+			Name:               "keep-structure",
+			Usage:              "with --to, keep files' original layout relative to their common parent directory",
+			Aliases:            []string{"k"},
+			DisableDefaultText: true,
+			Destination:        &keepStructure,
+		},
+		&cli.StringFlag{
+			Name:        "merge-conflict",
+			Usage:       "when merging directories, `POLICY` for each file that already exists (skip, overwrite, rename, backup, ask)",
+			Destination: &mergeConflictArg,
+		},
+		&cli.StringFlag{
+			Name:        "rename-template",
+			Usage:       "name files restored with the rename policy after `TEMPLATE` ({name}, {ext}, {date}, {time})",
+			Destination: &renameArg,
+		},
 	}
 
 	cleanRestoreFlags = []cli.Flag{
@@ -483,7 +974,7 @@ func main() {
 		Before:                 beforeAll,
 		After:                  after,
 		Action:                 action,
-		Commands:               []*cli.Command{doTrash, doList, doRestore, doClean},
+		Commands:               []*cli.Command{doTrash, doList, doRestore, doClean, doTag, doGrep, doCat, doDiff},
 		Flags:                  globalFlags,
 		UsageText:              appname + " [global options] [command [command options] / filename(s)]",
 		Description:            appdesc,
@@ -492,6 +983,249 @@ func main() {
 	}
 
 	if err := app.Run(os.Args); err != nil {
+		if log.GetLevel() > log.FatalLevel {
+			// logging is silenced without a terminal, but errors still matter
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 		log.Fatal(err)
 	}
 }
+
+// trashOptions returns the note and tags to record for trashed files.
+func trashOptions() files.TrashOptions {
+	return files.TrashOptions{Note: noteArg, Tags: tagArgs.Value()}
+}
+
+// inputPaths reads paths to trash from stdin and/or the file given to --files-from.
+func inputPaths() ([]string, error) {
+	var paths []string
+
+	if stdinArg || filesFromArg == "-" {
+		p, err := files.ReadPaths(os.Stdin, nullArg)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, p...)
+	}
+
+	if filesFromArg != "" && filesFromArg != "-" {
+		file, err := os.Open(filesFromArg)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		p, err := files.ReadPaths(file, nullArg)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, p...)
+	}
+
+	return paths, nil
+}
+
+// restoreOptions builds restore options from the config, overridden by flags.
+func restoreOptions() (files.RestoreOptions, error) {
+	policy := cfg.Restore.OnConflict
+	if onConflictArg != "" {
+		policy = onConflictArg
+	}
+	conflict, err := files.ParseConflict(policy)
+	if err != nil {
+		return files.RestoreOptions{}, err
+	}
+
+	mergePolicy := cfg.Restore.MergeConflict
+	if mergeConflictArg != "" {
+		mergePolicy = mergeConflictArg
+	}
+	mergeConflict, err := files.ParseConflict(mergePolicy)
+	if err != nil {
+		return files.RestoreOptions{}, err
+	}
+	if mergeConflict == files.Merge {
+		return files.RestoreOptions{}, fmt.Errorf("merge can't be used for files that conflict while merging")
+	}
+
+	template := cfg.Restore.RenameTemplate
+	if renameArg != "" {
+		template = renameArg
+	}
+
+	var to string
+	if restoreTo != "" {
+		if to, err = filepath.Abs(restoreTo); err != nil {
+			return files.RestoreOptions{}, err
+		}
+	}
+
+	return files.RestoreOptions{
+		OnConflict:     conflict,
+		MergeConflict:  mergeConflict,
+		RenameTemplate: template,
+		To:             to,
+		KeepStructure:  keepStructure,
+	}, nil
+}
+
+// restoreHereOptions builds restore options for restoring into the working directory.
+func restoreHereOptions() (files.RestoreOptions, error) {
+	opts, err := restoreOptions()
+	if err != nil {
+		return opts, err
+	}
+
+	opts.To, err = os.Getwd()
+	opts.KeepStructure = false
+	return opts, err
+}
+
+// restoreAsOf restores everything trashed from under --original-path, or the working
+// directory, after --as-of, to put the tree back the way it was at that time.
+func restoreAsOf(opts files.RestoreOptions) error {
+	asOf, err := anytime.Parse(asOfArg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	dir := ogdir
+	if dir == "" {
+		dir = "."
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return err
+	}
+
+	fls := files.AsOf(files.FindInAllTrashes("", fltr), dir, asOf)
+	if len(fls) == 0 {
+		fmt.Fprintf(os.Stdout, "nothing was trashed from %s after %s\n", dir, asOf.Format(time.DateTime))
+		return nil
+	}
+
+	return files.ConfirmRestorePlan(fls, opts)
+}
+
+// restoreVersions restores the --version of each path in args that was trashed more than once.
+func restoreVersions(args []string, opts files.RestoreOptions) error {
+	if len(args) == 0 {
+		return fmt.Errorf("--version needs the path of a file to restore")
+	}
+
+	var (
+		selected files.Files
+		versions = files.Versions(files.FindInAllTrashes(ogdir, fltr))
+	)
+
+	for _, arg := range args {
+		path, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+
+		fls, ok := versions[path]
+		if !ok {
+			// maybe it's just a name
+			var matches []string
+			for p := range versions {
+				if filepath.Base(p) == arg {
+					matches = append(matches, p)
+				}
+			}
+			switch len(matches) {
+			case 0:
+				return fmt.Errorf("nothing trashed from %s", path)
+			case 1:
+				fls = versions[matches[0]]
+			default:
+				slices.Sort(matches)
+				return fmt.Errorf("%s was trashed from more than one place: %s", arg, strings.Join(matches, ", "))
+			}
+		}
+
+		file, err := files.PickVersion(fls, versionArg)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, file)
+	}
+
+	return files.ConfirmRestore(askconfirm, selected, opts)
+}
+
+// findTrashed finds files in the trash matching the filter, and inside trashed directories
+// with --deep, narrowed down to any @ids given.
+func findTrashed() (files.Files, error) {
+	var fls files.Files
+	if deepArg {
+		fls = files.FindDeep(ogdir, fltr)
+	} else {
+		fls = files.FindInAllTrashes(ogdir, fltr)
+	}
+	if len(idArgs) == 0 {
+		return fls, nil
+	}
+	return files.ByID(fls, idArgs...)
+}
+
+// findGiven finds the trashed files named, or given by @id, as args, or matching the
+// filter, for commands that need to be given files, and only operate on one version of each.
+func findGiven(ctx *cli.Context) (files.Files, error) {
+	if !ctx.Args().Present() && fltr.Blank() {
+		return nil, fmt.Errorf("no files given")
+	}
+
+	fls, err := findTrashed()
+	if err != nil {
+		return nil, err
+	}
+	if len(fls) == 0 {
+		return nil, fmt.Errorf("no files in the trash matched")
+	}
+
+	return fls, files.Ambiguous(fls)
+}
+
+// selectTrashed shows the table to pick from fls, unless files were picked by @id, or
+// --all was given without a terminal to show it on, in which case all are picked.
+func selectTrashed(fls files.Files, mode modes.Mode) (files.Files, modes.Mode, error) {
+	if len(idArgs) > 0 || (all && !isTerminal) {
+		return fls, mode, nil
+	}
+
+	return interactive.Select(fls, all, all, workdir, mode)
+}
+
+// setupTrashedFilter makes the filter for commands operating on files in the trash,
+// taking args as names to filter by, or @ids.
+func setupTrashedFilter(args []string) (err error) {
+	if fltr == nil {
+		md, e := filemode.Parse(modeArg)
+		if e != nil {
+			return e
+		}
+		var names []string
+		for _, arg := range args {
+			if files.IsID(arg) {
+				idArgs = append(idArgs, arg)
+			} else {
+				names = append(names, arg)
+			}
+		}
+		if versionArg != "" {
+			// these are paths to pick versions of, not names to filter by
+			names = nil
+		}
+		fltr, err = filter.New(onArg, beforeArg, afterArg, globArg, patternArg, unGlobArg, unPatternArg, filesOnlyArg, dirsOnlyArg, false, minArg, maxArg, md, names...)
+		if err != nil {
+			return err
+		}
+		fltr.AddTags(tagFilterArgs.Value()...)
+		if err = fltr.SetNoteMatch(noteMatchArg); err != nil {
+			return err
+		}
+	}
+	log.Debugf("filter: %s", fltr.String())
+	return
+}